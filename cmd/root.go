@@ -79,9 +79,9 @@ var day7Cmd = &cobra.Command{
 }
 
 var day8Cmd = &cobra.Command{
-	Use:   "day8 [input file] [iterations]",
+	Use:   "day8 [input file]",
 	Short: "Day 8: Playground",
-	Args:  cobra.ExactArgs(2),
+	Args:  cobra.ExactArgs(1),
 	Run:   day8.Run,
 }
 
@@ -133,6 +133,53 @@ func init() {
 	// when this action is called directly.
 	//rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
+	day4Cmd.Flags().Bool("visualize", false, "Print each paper removal wave to the terminal")
+	day4Cmd.Flags().String("gif", "", "Export the paper removal waves as an animated GIF to the given path")
+	day4Cmd.Flags().Int("delay", 0, "Delay in milliseconds between visualization frames (GIFs default to 500)")
+
+	day5Cmd.Flags().String("serve", "", "Answer freshness queries instead of solving (stdin or http)")
+	day5Cmd.Flags().Bool("big", false, "Parse product IDs as arbitrary-precision integers")
+	day5Cmd.Flags().String("addr", "127.0.0.1:8080", "Loopback address to listen on in http serve mode")
+
+	day6Cmd.Flags().Bool("explain", false, "Print each problem's operands, operator and result in worksheet order")
+	day6Cmd.Flags().String("direction", "ltr", "Direction to read part 2 problem columns in (ltr or rtl)")
+
+	day7Cmd.Flags().Int("paths", 0, "Print the first N timelines in left/right order")
+	day7Cmd.Flags().Int("sample", 0, "Print N timelines picked uniformly at random")
+	day7Cmd.Flags().Uint64("seed", 1, "Random seed used when sampling timelines")
+	day7Cmd.Flags().Int("path-index", 0, "Print the timeline at the given 0-based index in left/right order")
+	day7Cmd.Flags().Bool("draw", false, "Draw timelines onto the grid instead of listing the splitters hit")
+	day7Cmd.Flags().Bool("render", false, "Print the grid with simulated beams and per-column timeline counts")
+	day7Cmd.Flags().String("svg", "", "Write the simulated beam grid as an SVG image to the given path")
+
+	day8Cmd.Flags().Int("iterations", 1000, "Number of closest connections to make for part 1")
+	day8Cmd.Flags().Int("top", 3, "Number of largest circuits to combine for part 1")
+	day8Cmd.Flags().String("aggregate", "product", "How to combine the largest circuits for part 1 (product, sum or histogram)")
+	day8Cmd.Flags().Int("until", 0, "Report how many connections are made before the largest circuit exceeds this size")
+	day8Cmd.Flags().String("metric", "euclidean", "Distance metric between boxes (euclidean, squared, manhattan or chebyshev)")
+	day8Cmd.Flags().String("mst", "", "Print the full wiring plan instead of solving (text, csv, dot or json)")
+
+	day9Cmd.Flags().Bool("validate", false, "Check the loop of red tiles for problems instead of solving")
+	day9Cmd.Flags().Bool("repair", false, "Drop redundant vertices from the loop of red tiles before solving")
+	day9Cmd.Flags().Int("top", 1, "List the K largest part 1 rectangles")
+	day9Cmd.Flags().String("svg", "", "Draw the floor and winning rectangles as an SVG image to the given path")
+
+	day10Cmd.Flags().Bool("explain", false, "Print the buttons to press for each machine and the state after each press")
+	day10Cmd.Flags().Int("jobs", 0, "Number of machines to solve in parallel (defaults to GOMAXPROCS)")
+	day10Cmd.Flags().Duration("timeout", 0, "Give up on any machine that takes longer than this to solve")
+	day10Cmd.Flags().Int("slowest", 0, "Report the N machines that took longest to solve")
+
+	day11Cmd.Flags().String("cycles", "error", "How to count paths through cycles (error, simple or infinite)")
+	day11Cmd.Flags().String("from", "svr", "Device to count paths from instead of solving")
+	day11Cmd.Flags().String("to", "out", "Device to count paths to instead of solving")
+	day11Cmd.Flags().StringSlice("via", []string{}, "Devices every path must pass through, in any order")
+	day11Cmd.Flags().StringSlice("avoid", []string{}, "Devices no path may pass through")
+	day11Cmd.Flags().Bool("parallel-edges", false, "Count a device listing the same output twice as two separate wires")
+	day11Cmd.Flags().String("export", "", "Print the device graph instead of solving (dot, mermaid or json)")
+	day11Cmd.Flags().Bool("on-path", false, "Only export devices lying on a path from you to out")
+
+	day12Cmd.Flags().Bool("report", false, "Classify each region by how far the area and 3x3 slot bounds settle it")
+
 	rootCmd.AddCommand(day1Cmd)
 	rootCmd.AddCommand(day2Cmd)
 	rootCmd.AddCommand(day3Cmd)
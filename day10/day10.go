@@ -1,13 +1,14 @@
 package day10
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 
-	"github.com/dominikbraun/graph"
 	"github.com/spf13/cobra"
 )
 
@@ -100,128 +101,51 @@ func Run(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
-	machines := parseInput(string(contents))
-
-	fmt.Println("Part 1:", part1(machines))
-	fmt.Println("Run day10-part2.cs for part 2")
-}
-
-func part1(machines []machine) int {
-	result := 0
-
-	for _, machine := range machines {
-		sourceState := machine.indicatorState
-		targetState := machine.indicatorTarget
-
-		g := part1Graph(machine)
-		path, err := graph.ShortestPath(g, sourceState.String(), targetState.String())
-
-		if err != nil {
-			panic(err)
-		}
-
-		result += (len(path) - 1)
+	machines, err := parseInput(string(contents))
+	if err != nil {
+		panic(err)
 	}
 
-	return result
-}
-
-func part1Graph(m machine) graph.Graph[string, machineIndicatorState] {
-	g := graph.New(func(ms machineIndicatorState) string {
-		return ms.String()
-	})
-
-	// Add source state
-	g.AddVertex(m.indicatorState)
-
-	part1PressButtons(g, m.indicatorState, m.buttons, m.indicatorTarget)
-
-	return g
-}
-
-func part1PressButtons(g graph.Graph[string, machineIndicatorState], state machineIndicatorState, btns []button, target machineIndicatorState) {
-	queue := []machineIndicatorState{}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
 
-	for _, btn := range btns {
-		nextState := btn.pressIndicator(state)
-		err := g.AddVertex(nextState)
+	explain, _ := cmd.Flags().GetBool("explain")
 
-		if err == nil {
-			queue = append(queue, nextState)
-		}
-		g.AddEdge(state.String(), nextState.String())
+	results := solveMachines(context.Background(), machines, jobs, timeout, explain)
 
-		// End now if we've hit the target
-		if nextState.String() == target.String() {
-			return
-		}
+	if explain {
+		explainMachines(os.Stdout, machines, results)
 	}
 
-	for _, nextState := range queue {
-		part1PressButtons(g, nextState, btns, target)
+	if slowest, _ := cmd.Flags().GetInt("slowest"); slowest > 0 {
+		printSlowest(os.Stdout, results, slowest)
 	}
-}
 
-// Parse input into machines
-func parseInput(input string) []machine {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
-	machines := []machine{}
-
-	indicatorPattern := regexp.MustCompile(`\[([\.#]+)\]`)
-	buttonsPattern := regexp.MustCompile(`\(([\d,]+)\)`)
-	joltagesPattern := regexp.MustCompile(`\{([\d,]+)\}`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		indicatorsResult := indicatorPattern.FindStringSubmatch(line)
-		joltagesResult := joltagesPattern.FindStringSubmatch(line)
-		buttonResults := buttonsPattern.FindAllStringSubmatch(line, -1)
-
-		indicators := []bool{}
-		joltages := []int{}
-		buttons := []button{}
+	part1Result, err := part1(results)
+	if err != nil {
+		panic(err)
+	}
 
-		for _, light := range indicatorsResult[1] {
-			indicators = append(indicators, light == '#')
-		}
+	fmt.Println("Part 1:", part1Result)
+	fmt.Println("Run day10-part2.cs for part 2")
+}
 
-		joltagesSplits := strings.Split(joltagesResult[1], ",")
-		for _, joltage := range joltagesSplits {
-			joltageVal, _ := strconv.Atoi(joltage)
-			joltages = append(joltages, joltageVal)
-		}
+// Part 1 result is the fewest presses needed to light up every machine's indicators
+func part1(results []machineResult) (int, error) {
+	total := 0
+	errs := []error{}
 
-		/*
-			for _, joltage := range joltagesResult[1] {
-				joltageVal, err := strconv.Atoi(string(joltage))
-				if err != nil {
-					panic(err)
-				}
-				joltages = append(joltages, joltageVal)
-			}*/
-
-		for _, buttonResult := range buttonResults {
-			parsed := strings.Split(buttonResult[1], ",")
-			wires := []int{}
-			for _, wire := range parsed {
-				v, _ := strconv.Atoi(wire)
-				wires = append(wires, v)
-			}
-			buttons = append(buttons, button{wires})
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
 		}
 
-		indicatorState := make(machineIndicatorState, len(indicators))
-		joltageState := make(machineJoltageState, len(joltages))
-
-		machine := machine{
-			indicatorState:  indicatorState,
-			indicatorTarget: indicators,
-			joltageState:    joltageState,
-			joltageTarget:   joltages,
-			buttons:         buttons,
-		}
-		machines = append(machines, machine)
+		total += result.presses
 	}
 
-	return machines
+	return total, errors.Join(errs...)
 }
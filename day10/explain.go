@@ -0,0 +1,327 @@
+package day10
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Buttons to press to reach a target, along with how many other ways there are to do it
+type solution struct {
+	// How many times each button is pressed, in button order
+	presses []int
+	total   int
+
+	// Number of different sets of presses that reach the target with the same total
+	optimal int
+}
+
+func (btn button) String() string {
+	wires := []string{}
+	for _, wire := range btn.wires {
+		wires = append(wires, strconv.Itoa(wire))
+	}
+
+	return "(" + strings.Join(wires, ",") + ")"
+}
+
+// Print the presses for each machine and the state after each one
+// Solutions come from the results of solving the machines with joltages included
+func explainMachines(out io.Writer, machines []machine, results []machineResult) {
+	for i, m := range machines {
+		buttons := []string{}
+		for _, btn := range m.buttons {
+			buttons = append(buttons, btn.String())
+		}
+
+		fmt.Fprintf(out, "Machine %d: [%s] %s {%s}\n", i+1, m.indicatorTarget, strings.Join(buttons, " "), m.joltageTarget)
+
+		if result := results[i]; result.err == nil {
+			sol := result.indicators
+			fmt.Fprintf(out, "  Indicators: %d presses (%s)\n", sol.total, describeOptimal(sol.optimal))
+
+			state := m.indicatorState
+			for b, cnt := range sol.presses {
+				if cnt > 0 {
+					state = m.buttons[b].pressIndicator(state)
+					fmt.Fprintf(out, "    %s -> [%s]\n", m.buttons[b], state)
+				}
+			}
+		} else {
+			fmt.Fprintln(out, "  Indicators:", result.err)
+		}
+
+		if result := results[i]; result.joltageErr == nil {
+			sol := result.joltages
+			fmt.Fprintf(out, "  Joltages: %d presses (%s)\n", sol.total, describeOptimal(sol.optimal))
+
+			state := m.joltageState
+			for b, cnt := range sol.presses {
+				for range cnt {
+					state = m.buttons[b].pressJoltage(state)
+					fmt.Fprintf(out, "    %s -> {%s}\n", m.buttons[b], state)
+				}
+			}
+		} else {
+			fmt.Fprintln(out, "  Joltages:", result.joltageErr)
+		}
+	}
+}
+
+func describeOptimal(cnt int) string {
+	if cnt == 1 {
+		return "unique"
+	}
+
+	return fmt.Sprintf("one of %d optimal solutions", cnt)
+}
+
+// Find the fewest button presses that light up the indicators
+// Pressing a button twice undoes it, so a solution is a set of buttons each pressed once.
+// A breadth first search over indicator states counts the shortest press sequences, and every
+// ordering of the same set of buttons is one of those sequences.
+// Gives up with the context's error if it's cancelled first
+func solveIndicators(ctx context.Context, m machine) (solution, error) {
+	type visit struct {
+		depth  int
+		paths  int
+		from   string
+		button int
+	}
+
+	start := m.indicatorState.String()
+	target := m.indicatorTarget.String()
+
+	visited := map[string]visit{start: {0, 1, "", -1}}
+	queue := []machineIndicatorState{m.indicatorState}
+
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return solution{}, err
+		}
+
+		state := queue[0]
+		queue = queue[1:]
+		cur := visited[state.String()]
+
+		if state.String() == target {
+			break
+		}
+
+		for b, btn := range m.buttons {
+			next := btn.pressIndicator(state)
+			key := next.String()
+
+			if v, ok := visited[key]; !ok {
+				visited[key] = visit{cur.depth + 1, cur.paths, state.String(), b}
+				queue = append(queue, next)
+			} else if v.depth == cur.depth+1 {
+				v.paths += cur.paths
+				visited[key] = v
+			}
+		}
+	}
+
+	end, ok := visited[target]
+	if !ok {
+		return solution{}, errUnreachableLights
+	}
+
+	sol := solution{presses: make([]int, len(m.buttons)), total: end.depth, optimal: end.paths}
+	for key := target; key != start; key = visited[key].from {
+		sol.presses[visited[key].button]++
+	}
+
+	// Each set of buttons was counted once for every order it can be pressed in
+	for i := 2; i <= end.depth; i++ {
+		sol.optimal /= i
+	}
+
+	return sol, nil
+}
+
+// Joltage targets as a system of linear equations in reduced row echelon form
+// Row r solves for the button in `pivots[r]` in terms of the `free` buttons
+type joltageSystem struct {
+	rows   [][]int
+	pivots []int
+	free   []int
+
+	// Most times each button can be pressed before overshooting a counter
+	bounds []int
+}
+
+// Turn the joltage targets into a system of equations, one per counter, over the number of times
+// each button is pressed. Elimination is done fraction free so everything stays in integers.
+// Returns false if the equations contradict each other.
+func reduceJoltages(m machine) (joltageSystem, bool) {
+	numButtons := len(m.buttons)
+	sys := joltageSystem{
+		rows:   make([][]int, len(m.joltageTarget)),
+		pivots: []int{},
+		free:   []int{},
+		bounds: make([]int, numButtons),
+	}
+
+	for i, target := range m.joltageTarget {
+		sys.rows[i] = make([]int, numButtons+1)
+		sys.rows[i][numButtons] = target - m.joltageState[i]
+	}
+
+	for b, btn := range m.buttons {
+		sys.bounds[b] = -1
+		for _, wire := range btn.wires {
+			sys.rows[wire][b]++
+
+			remaining := m.joltageTarget[wire] - m.joltageState[wire]
+			if sys.bounds[b] < 0 || remaining < sys.bounds[b] {
+				sys.bounds[b] = remaining
+			}
+		}
+
+		// Pressing a button with no wires does nothing, so there's never a reason to
+		sys.bounds[b] = max(sys.bounds[b], 0)
+	}
+
+	for col := range numButtons {
+		rank := len(sys.pivots)
+
+		pivot := -1
+		for r := rank; r < len(sys.rows); r++ {
+			if sys.rows[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+
+		if pivot < 0 {
+			sys.free = append(sys.free, col)
+			continue
+		}
+
+		sys.rows[rank], sys.rows[pivot] = sys.rows[pivot], sys.rows[rank]
+		for r := range sys.rows {
+			if r != rank && sys.rows[r][col] != 0 {
+				eliminate(sys.rows[r], sys.rows[rank], col)
+			}
+		}
+
+		sys.pivots = append(sys.pivots, col)
+	}
+
+	// Leftover equations have no buttons left in them, so their targets have to be 0 already
+	for _, row := range sys.rows[len(sys.pivots):] {
+		if row[numButtons] != 0 {
+			return sys, false
+		}
+	}
+
+	return sys, true
+}
+
+// Find the fewest button presses that bring every joltage counter up to its target
+// Once the equations are reduced, trying every press count for the free buttons fixes the rest
+// Gives up with the context's error if it's cancelled first
+func solveJoltages(ctx context.Context, m machine) (solution, error) {
+	if err := ctx.Err(); err != nil {
+		return solution{}, err
+	}
+
+	sys, ok := reduceJoltages(m)
+	if !ok {
+		return solution{}, errUnreachableJolts
+	}
+
+	numButtons := len(m.buttons)
+	best := solution{total: -1}
+	presses := make([]int, numButtons)
+
+	var try func(i, subtotal int)
+	try = func(i, subtotal int) {
+		if ctx.Err() != nil || (best.total >= 0 && subtotal > best.total) {
+			return
+		}
+
+		if i < len(sys.free) {
+			for cnt := 0; cnt <= sys.bounds[sys.free[i]]; cnt++ {
+				presses[sys.free[i]] = cnt
+				try(i+1, subtotal+cnt)
+			}
+			presses[sys.free[i]] = 0
+			return
+		}
+
+		total := subtotal
+		for r, col := range sys.pivots {
+			remaining := sys.rows[r][numButtons]
+			for _, f := range sys.free {
+				remaining -= sys.rows[r][f] * presses[f]
+			}
+
+			if remaining%sys.rows[r][col] != 0 {
+				return
+			}
+
+			cnt := remaining / sys.rows[r][col]
+			if cnt < 0 || cnt > sys.bounds[col] {
+				return
+			}
+
+			presses[col] = cnt
+			total += cnt
+		}
+
+		if best.total < 0 || total < best.total {
+			best = solution{presses: append([]int{}, presses...), total: total, optimal: 1}
+		} else if total == best.total {
+			best.optimal++
+		}
+	}
+
+	try(0, 0)
+
+	if err := ctx.Err(); err != nil {
+		return solution{}, err
+	}
+
+	if best.total < 0 {
+		return solution{}, errUnreachableJolts
+	}
+
+	return best, nil
+}
+
+// Remove `col` from `row` using the pivot row, keeping all the values integers
+func eliminate(row, pivotRow []int, col int) {
+	a := row[col]
+	p := pivotRow[col]
+
+	divisor := 0
+	for i := range row {
+		row[i] = row[i]*p - pivotRow[i]*a
+		divisor = gcd(divisor, row[i])
+	}
+
+	if divisor > 1 {
+		for i := range row {
+			row[i] /= divisor
+		}
+	}
+}
+
+func gcd(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
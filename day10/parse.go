@@ -0,0 +1,305 @@
+package day10
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error found while parsing the input
+// `line` and `col` are 1-based, with a column of 0 meaning the problem is with the machine as a whole
+type parseError struct {
+	line, col int
+	err       error
+}
+
+func (e parseError) Error() string {
+	if e.col == 0 {
+		return fmt.Sprintf("line %d: %v", e.line, e.err)
+	}
+
+	return fmt.Sprintf("line %d, column %d: %v", e.line, e.col, e.err)
+}
+
+func (e parseError) Unwrap() error {
+	return e.err
+}
+
+var (
+	errUnexpected        = errors.New("unexpected character")
+	errUnclosed          = errors.New("unclosed bracket")
+	errNoIndicators      = errors.New("missing [indicators]")
+	errNoJoltages        = errors.New("missing {joltages}")
+	errExtraIndicators   = errors.New("more than one [indicators]")
+	errExtraJoltages     = errors.New("more than one {joltages}")
+	errOutOfOrder        = errors.New("expected [indicators] (buttons) {joltages} in that order")
+	errBadLight          = errors.New("indicator must be . or #")
+	errBadNumber         = errors.New("value must be a non-negative integer")
+	errEmptyList         = errors.New("list is empty")
+	errWireRange         = errors.New("wire out of range")
+	errDuplicateWire     = errors.New("wire listed twice")
+	errJoltageCount      = errors.New("joltage count doesn't match indicator count")
+	errUnreachableLights = errors.New("indicators can't be reached with these buttons")
+	errUnreachableJolts  = errors.New("joltages can't be reached with these buttons")
+)
+
+// Bracketed section of a machine line
+// `kind` is the opening bracket and `col` the 1-based column it's in
+type token struct {
+	kind byte
+	col  int
+	body string
+}
+
+var closers = map[byte]byte{'[': ']', '(': ')', '{': '}'}
+
+// Split a machine line into its bracketed sections
+func tokenize(line string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == ' ' || c == '\t' || c == '\r' {
+			continue
+		}
+
+		closer, ok := closers[c]
+		if !ok {
+			return nil, parseError{col: i + 1, err: fmt.Errorf("%w %q", errUnexpected, c)}
+		}
+
+		end := strings.IndexByte(line[i+1:], closer)
+		if end < 0 {
+			return nil, parseError{col: i + 1, err: errUnclosed}
+		}
+
+		body := line[i+1 : i+1+end]
+		if j := strings.IndexAny(body, "[](){}"); j >= 0 {
+			return nil, parseError{col: i + 2 + j, err: fmt.Errorf("%w %q", errUnexpected, body[j])}
+		}
+
+		tokens = append(tokens, token{c, i + 1, body})
+		i += end + 1
+	}
+
+	return tokens, nil
+}
+
+// Parse a comma separated list of numbers from the body of a token
+func parseNumbers(tok token) ([]int, error) {
+	if strings.TrimSpace(tok.body) == "" {
+		return nil, parseError{col: tok.col, err: errEmptyList}
+	}
+
+	values := []int{}
+	offset := 0
+
+	for _, field := range strings.Split(tok.body, ",") {
+		col := tok.col + 1 + offset
+		offset += len(field) + 1
+
+		field = strings.TrimSpace(field)
+		v, err := strconv.Atoi(field)
+		if err != nil || v < 0 || strings.HasPrefix(field, "+") {
+			return nil, parseError{col: col, err: fmt.Errorf("%w, got %q", errBadNumber, field)}
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// Parse a single line into a machine
+// Errors have their column set but not their line
+func parseMachine(line string) (machine, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return machine{}, err
+	}
+
+	var indicators *token
+	var joltages *token
+	buttonTokens := []token{}
+
+	for i, tok := range tokens {
+		switch tok.kind {
+		case '[':
+			if indicators != nil {
+				return machine{}, parseError{col: tok.col, err: errExtraIndicators}
+			}
+			if i != 0 {
+				return machine{}, parseError{col: tok.col, err: errOutOfOrder}
+			}
+			indicators = &tokens[i]
+		case '(':
+			if joltages != nil {
+				return machine{}, parseError{col: tok.col, err: errOutOfOrder}
+			}
+			buttonTokens = append(buttonTokens, tok)
+		case '{':
+			if joltages != nil {
+				return machine{}, parseError{col: tok.col, err: errExtraJoltages}
+			}
+			joltages = &tokens[i]
+		}
+	}
+
+	if indicators == nil {
+		return machine{}, parseError{col: 1, err: errNoIndicators}
+	}
+	if joltages == nil {
+		return machine{}, parseError{col: len(line) + 1, err: errNoJoltages}
+	}
+
+	m := machine{}
+
+	if indicators.body == "" {
+		return machine{}, parseError{col: indicators.col, err: errEmptyList}
+	}
+	for i, light := range indicators.body {
+		if light != '.' && light != '#' {
+			return machine{}, parseError{col: indicators.col + 1 + i, err: fmt.Errorf("%w, got %q", errBadLight, light)}
+		}
+		m.indicatorTarget = append(m.indicatorTarget, light == '#')
+	}
+
+	for _, tok := range buttonTokens {
+		wires, err := parseNumbers(tok)
+		if err != nil {
+			return machine{}, err
+		}
+
+		seen := make(map[int]bool)
+		for _, wire := range wires {
+			if wire >= len(m.indicatorTarget) {
+				return machine{}, parseError{col: tok.col, err: fmt.Errorf("%w: button %s uses wire %d but there are only %d lights", errWireRange, tok.body, wire, len(m.indicatorTarget))}
+			}
+			if seen[wire] {
+				return machine{}, parseError{col: tok.col, err: fmt.Errorf("%w: button %s uses wire %d more than once", errDuplicateWire, tok.body, wire)}
+			}
+			seen[wire] = true
+		}
+
+		m.buttons = append(m.buttons, button{wires})
+	}
+
+	if m.joltageTarget, err = parseNumbers(*joltages); err != nil {
+		return machine{}, err
+	}
+	if len(m.joltageTarget) != len(m.indicatorTarget) {
+		return machine{}, parseError{col: joltages.col, err: fmt.Errorf("%w: %d joltages for %d lights", errJoltageCount, len(m.joltageTarget), len(m.indicatorTarget))}
+	}
+
+	m.indicatorState = make(machineIndicatorState, len(m.indicatorTarget))
+	m.joltageState = make(machineJoltageState, len(m.joltageTarget))
+
+	if err := checkMachine(m); err != nil {
+		return machine{}, parseError{err: err}
+	}
+
+	return m, nil
+}
+
+// Check that both targets can be reached before spending time searching for the fewest presses
+// Indicators are checked exactly by elimination over GF(2). Joltages are checked for counters no
+// button is wired to and for equations that contradict each other, but press counts that would
+// have to be fractional or negative are only found by solving.
+func checkMachine(m machine) error {
+	// Each row is a light, with a column per button and the target in the last column
+	numButtons := len(m.buttons)
+	rows := make([][]bool, len(m.indicatorTarget))
+	for i, on := range m.indicatorTarget {
+		rows[i] = make([]bool, numButtons+1)
+		rows[i][numButtons] = on != m.indicatorState[i]
+	}
+	for b, btn := range m.buttons {
+		for _, wire := range btn.wires {
+			rows[wire][b] = !rows[wire][b]
+		}
+	}
+
+	rank := 0
+	for b := range numButtons {
+		pivot := -1
+		for r := rank; r < len(rows); r++ {
+			if rows[r][b] {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+		for r := range rows {
+			if r != rank && rows[r][b] {
+				for c := range rows[r] {
+					rows[r][c] = rows[r][c] != rows[rank][c]
+				}
+			}
+		}
+		rank++
+	}
+
+	// Leftover rows have no buttons left in them, so their targets have to be off
+	for _, row := range rows[rank:] {
+		if row[numButtons] {
+			return errUnreachableLights
+		}
+	}
+
+	for i, target := range m.joltageTarget {
+		if target == m.joltageState[i] {
+			continue
+		}
+
+		wired := false
+		for _, btn := range m.buttons {
+			for _, wire := range btn.wires {
+				wired = wired || wire == i
+			}
+		}
+
+		if !wired {
+			return fmt.Errorf("%w: no button is wired to counter %d", errUnreachableJolts, i)
+		}
+	}
+
+	if _, ok := reduceJoltages(m); !ok {
+		return errUnreachableJolts
+	}
+
+	return nil
+}
+
+// Parse input into machines
+// Every line is checked, with all the problems found returned together
+func parseInput(input string) ([]machine, error) {
+	lines := strings.Split(input, "\n")
+	machines := []machine{}
+	errs := []error{}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		m, err := parseMachine(line)
+		if err != nil {
+			var pe parseError
+			if errors.As(err, &pe) {
+				pe.line = i + 1
+				err = pe
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		machines = append(machines, m)
+	}
+
+	return machines, errors.Join(errs...)
+}
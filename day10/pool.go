@@ -0,0 +1,104 @@
+package day10
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Outcome of solving a single machine
+type machineResult struct {
+	index   int
+	presses int
+	elapsed time.Duration
+	err     error
+
+	// Solutions kept for explaining, joltages are only solved when explaining
+	indicators solution
+	joltages   solution
+	joltageErr error
+}
+
+// Solve the indicators of every machine using a pool of `jobs` workers
+// Results come back in machine order regardless of which worker finished first.
+// A `timeout` above 0 limits how long each machine can take.
+// With `explain` set, the joltages are solved too, within the same time limit.
+func solveMachines(ctx context.Context, machines []machine, jobs int, timeout time.Duration, explain bool) []machineResult {
+	results := make([]machineResult, len(machines))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for range max(jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				results[i] = solveMachine(ctx, i, machines[i], timeout, explain)
+			}
+		}()
+	}
+
+	for i := range machines {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func solveMachine(ctx context.Context, index int, m machine, timeout time.Duration, explain bool) machineResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	sol, err := solveIndicators(ctx, m)
+	result := machineResult{index: index, presses: sol.total, indicators: sol}
+	result.err = machineError(index, timeout, err)
+
+	if explain {
+		result.joltages, err = solveJoltages(ctx, m)
+		result.joltageErr = machineError(index, timeout, err)
+	}
+
+	result.elapsed = time.Since(start)
+
+	return result
+}
+
+// Label an error from solving a machine with the machine's number
+func machineError(index int, timeout time.Duration, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("machine %d: timed out after %s", index+1, timeout)
+	} else if err != nil {
+		return fmt.Errorf("machine %d: %w", index+1, err)
+	}
+
+	return nil
+}
+
+// Print the `n` machines that took longest to solve, slowest first
+func printSlowest(out io.Writer, results []machineResult, n int) {
+	sorted := slices.Clone(results)
+	slices.SortFunc(sorted, func(a, b machineResult) int {
+		return cmp.Or(cmp.Compare(b.elapsed, a.elapsed), cmp.Compare(a.index, b.index))
+	})
+
+	fmt.Fprintln(out, "Slowest machines:")
+	for _, result := range sorted[:min(n, len(sorted))] {
+		if result.err != nil {
+			fmt.Fprintf(out, "  Machine %d: %s (%v)\n", result.index+1, result.elapsed, result.err)
+		} else {
+			fmt.Fprintf(out, "  Machine %d: %s (%d presses)\n", result.index+1, result.elapsed, result.presses)
+		}
+	}
+}
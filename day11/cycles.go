@@ -0,0 +1,302 @@
+package day11
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Cycle of devices lying on a path between the start and end, so there are infinitely many paths
+type cycleError struct {
+	nodes []string
+}
+
+func (e cycleError) Error() string {
+	return "devices loop through " + strings.Join(append(slices.Clone(e.nodes), e.nodes[0]), " -> ")
+}
+
+// How to handle cycles when counting paths
+type cycleMode string
+
+const (
+	// Fail with the cycle that was found
+	cycleModeError cycleMode = "error"
+	// Count paths that visit each device at most once
+	cycleModeSimple cycleMode = "simple"
+	// Report that there are infinitely many paths
+	cycleModeInfinite cycleMode = "infinite"
+)
+
+func parseCycleMode(s string) (cycleMode, error) {
+	switch mode := cycleMode(s); mode {
+	case cycleModeError, cycleModeSimple, cycleModeInfinite:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unknown cycle mode %q, expected error, simple or infinite", s)
+}
+
+// Function counting the paths between two devices
+type pathCounter func(g *deviceGraph, q pathQuery) (int, error)
+
+func (mode cycleMode) counter() pathCounter {
+	if mode == cycleModeSimple {
+		return countSimplePaths
+	}
+
+	return countPaths
+}
+
+// Format a path count, or the cycle that makes it infinite
+func (mode cycleMode) format(cnt int, err error) (string, error) {
+	var cycle cycleError
+	if mode == cycleModeInfinite && errors.As(err, &cycle) {
+		return "infinite", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(cnt), nil
+}
+
+// Find the devices that lie on some path between the query's devices without touching an avoided one
+// Devices that can't be reached from the start, or that can't reach the end, never change the count
+func pathNodes(g *deviceGraph, q resolvedQuery) []bool {
+	nodes := make([]bool, g.size())
+	if q.avoid[q.from] || q.avoid[q.to] {
+		return nodes
+	}
+
+	reachable := reach(q.from, g.outputs, q.avoid)
+	if !reachable[q.to] {
+		return nodes
+	}
+
+	for node, reached := range reach(q.to, g.inputs, q.avoid) {
+		nodes[node] = reached && reachable[node]
+	}
+
+	return nodes
+}
+
+// Breadth first search from `start`, returning every node reached without passing through a skipped one
+func reach(start int, neighbors func(node int) []int, skip []bool) []bool {
+	reached := make([]bool, len(skip))
+	reached[start] = true
+	queue := []int{start}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, neighbor := range neighbors(node) {
+			if !reached[neighbor] && !skip[neighbor] {
+				reached[neighbor] = true
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	return reached
+}
+
+// Find a cycle among the devices left over from a topological sort
+// Every leftover device still has an incoming edge from another leftover device, so walking
+// backwards along those edges has to come round to a device already seen
+func findCycle(g *deviceGraph, leftover []bool) []string {
+	// Follow the first device alphabetically at each step so the same cycle is reported every time
+	first := func(nodes []int) int {
+		best := -1
+		for _, node := range nodes {
+			if leftover[node] && (best < 0 || g.labels[node] < g.labels[best]) {
+				best = node
+			}
+		}
+		return best
+	}
+
+	all := []int{}
+	for node, left := range leftover {
+		if left {
+			all = append(all, node)
+		}
+	}
+
+	seen := make(map[int]int)
+	walk := []string{}
+
+	for node := first(all); ; node = first(g.inputs(node)) {
+		if i, ok := seen[node]; ok {
+			cycle := walk[i:]
+			slices.Reverse(cycle)
+			return cycle
+		}
+
+		seen[node] = len(walk)
+		walk = append(walk, g.labels[node])
+	}
+}
+
+// Most steps the search through cycles takes before giving up on counting simple paths
+const maxSimpleSteps = 1 << 24
+
+var errTooManySimplePaths = errors.New("cycles are too tangled to count the simple paths through them")
+
+// Count the paths matching the query that never visit the same device twice
+// Without a cycle on the way this is the same as countPaths. Otherwise the devices are grouped into
+// strongly connected components: a path can't come back to a component once it leaves, so counts are
+// passed between components in topological order, and only paths inside a component are walked one
+// at a time. Returns errTooManySimplePaths if that walk takes too long.
+func countSimplePaths(g *deviceGraph, q pathQuery) (int, error) {
+	cnt, err := countPaths(g, q)
+	var cycle cycleError
+	if !errors.As(err, &cycle) {
+		return cnt, err
+	}
+
+	// A cycle was found, so the query's devices are all in the graph
+	rq, _ := q.resolve(g)
+	nodes := pathNodes(g, rq)
+	components, component := stronglyConnected(g, nodes)
+
+	// dp[node*subsets+visited] counts paths entering node's component at node having visited that
+	// set of waypoints
+	subsets := q.allWaypoints() + 1
+	dp := make([]int, g.size()*subsets)
+	dp[rq.from*subsets+rq.bits[rq.from]] = 1
+
+	total := 0
+	steps := 0
+	onPath := make([]bool, g.size())
+
+	for _, members := range components {
+		for _, entry := range members {
+			counts := dp[entry*subsets : (entry+1)*subsets]
+
+			// Walk every simple path from the entry that stays inside the component,
+			// collecting the waypoints it passes through
+			var walk func(node int, collected int) error
+			walk = func(node int, collected int) error {
+				if steps++; steps > maxSimpleSteps {
+					return errTooManySimplePaths
+				}
+
+				if node == rq.to {
+					for visited, cnt := range counts {
+						if visited|collected == q.allWaypoints() {
+							total += cnt
+						}
+					}
+					return nil
+				}
+
+				onPath[node] = true
+				defer func() { onPath[node] = false }()
+
+				for _, neighbor := range g.outputs(node) {
+					if !nodes[neighbor] {
+						continue
+					}
+
+					bit := rq.bits[neighbor]
+					if component[neighbor] != component[node] {
+						for visited, cnt := range counts {
+							dp[neighbor*subsets+(visited|collected|bit)] += cnt
+						}
+						continue
+					}
+
+					if !onPath[neighbor] {
+						if err := walk(neighbor, collected|bit); err != nil {
+							return err
+						}
+					}
+				}
+
+				return nil
+			}
+
+			if slices.ContainsFunc(counts, func(cnt int) bool { return cnt != 0 }) {
+				if err := walk(entry, 0); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// Group the given devices into strongly connected components, listed in topological order
+// Also returns the index of each device's component, or -1 for devices that weren't given.
+// Uses Kosaraju's algorithm: a depth first search orders devices by when it finishes with them,
+// then searching backwards from the last to finish collects one component at a time.
+func stronglyConnected(g *deviceGraph, nodes []bool) ([][]int, []int) {
+	type frame struct {
+		node, next int
+	}
+
+	finished := []int{}
+	seen := make([]bool, g.size())
+
+	for start, ok := range nodes {
+		if !ok || seen[start] {
+			continue
+		}
+
+		seen[start] = true
+		stack := []frame{{start, 0}}
+
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			outputs := g.outputs(top.node)
+
+			if top.next < len(outputs) {
+				next := outputs[top.next]
+				top.next++
+
+				if nodes[next] && !seen[next] {
+					seen[next] = true
+					stack = append(stack, frame{next, 0})
+				}
+				continue
+			}
+
+			finished = append(finished, top.node)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	component := make([]int, g.size())
+	for node := range component {
+		component[node] = -1
+	}
+
+	components := [][]int{}
+	for i := len(finished) - 1; i >= 0; i-- {
+		start := finished[i]
+		if component[start] >= 0 {
+			continue
+		}
+
+		id := len(components)
+		component[start] = id
+		members := []int{start}
+
+		for j := 0; j < len(members); j++ {
+			for _, input := range g.inputs(members[j]) {
+				if nodes[input] && component[input] < 0 {
+					component[input] = id
+					members = append(members, input)
+				}
+			}
+		}
+
+		components = append(components, members)
+	}
+
+	return components, component
+}
@@ -0,0 +1,101 @@
+package day11
+
+import (
+	"slices"
+)
+
+// Device graph with labels interned to integer IDs and edges in compressed sparse row form
+// The outputs of device `id` are `targets[offsets[id]:offsets[id+1]]`, and the same goes for
+// inputs in the reverse arrays. Duplicate outputs listed for a device are merged into a single
+// edge unless the graph was built with parallel edges, in which case each one is a separate wire
+// and counts as a separate path.
+type deviceGraph struct {
+	labels []string
+	ids    map[string]int
+
+	offsets, targets       []int
+	revOffsets, revTargets []int
+}
+
+func newDeviceGraph(devices []device, parallel bool) *deviceGraph {
+	g := &deviceGraph{ids: make(map[string]int)}
+
+	intern := func(label string) int {
+		id, ok := g.ids[label]
+		if !ok {
+			id = len(g.labels)
+			g.ids[label] = id
+			g.labels = append(g.labels, label)
+		}
+		return id
+	}
+
+	// Collect edges as pairs first, then count them into rows
+	froms := []int{}
+	tos := []int{}
+	for _, d := range devices {
+		from := intern(d.label)
+		for _, o := range d.outputs {
+			froms = append(froms, from)
+			tos = append(tos, intern(o))
+		}
+	}
+
+	g.offsets, g.targets = buildRows(len(g.labels), froms, tos, parallel)
+	g.revOffsets, g.revTargets = buildRows(len(g.labels), tos, froms, parallel)
+
+	return g
+}
+
+// Build CSR rows from a list of edges, with each row's targets sorted
+func buildRows(n int, froms, tos []int, parallel bool) ([]int, []int) {
+	offsets := make([]int, n+1)
+	for _, from := range froms {
+		offsets[from+1]++
+	}
+	for i := range n {
+		offsets[i+1] += offsets[i]
+	}
+
+	targets := make([]int, len(tos))
+	next := slices.Clone(offsets[:n])
+	for i, from := range froms {
+		targets[next[from]] = tos[i]
+		next[from]++
+	}
+
+	// Sort each row, dropping repeated targets if edges are being merged
+	size := 0
+	for id := range n {
+		row := targets[offsets[id]:offsets[id+1]]
+		slices.Sort(row)
+		if !parallel {
+			row = slices.Compact(row)
+		}
+
+		start := size
+		size += copy(targets[size:], row)
+		offsets[id] = start
+	}
+	offsets[n] = size
+
+	return offsets, targets[:size]
+}
+
+func (g *deviceGraph) size() int {
+	return len(g.labels)
+}
+
+func (g *deviceGraph) outputs(id int) []int {
+	return g.targets[g.offsets[id]:g.offsets[id+1]]
+}
+
+func (g *deviceGraph) inputs(id int) []int {
+	return g.revTargets[g.revOffsets[id]:g.revOffsets[id+1]]
+}
+
+// Look up a device's ID, returning false if no device has that label
+func (g *deviceGraph) id(label string) (int, bool) {
+	id, ok := g.ids[label]
+	return id, ok
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/dominikbraun/graph"
 	"github.com/spf13/cobra"
 )
 
@@ -22,60 +21,144 @@ func Run(cmd *cobra.Command, args []string) {
 
 	devices := parseInput(string(contents))
 
-	g := graph.New(graph.StringHash, graph.Directed())
+	parallel, _ := cmd.Flags().GetBool("parallel-edges")
+	g := newDeviceGraph(devices, parallel)
 
-	for _, d := range devices {
-		g.AddVertex(d.label)
-		for _, o := range d.outputs {
-			g.AddVertex(o)
-			g.AddEdge(d.label, o)
+	modeFlag, _ := cmd.Flags().GetString("cycles")
+	mode, err := parseCycleMode(modeFlag)
+	if err != nil {
+		panic(err)
+	}
+
+	if format, _ := cmd.Flags().GetString("export"); format != "" {
+		// Label devices with the part 1 paths through them, unless a cycle makes that impossible
+		counts, err := nodePathCounts(g, "you", "out")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Not counting paths:", err)
+		}
+
+		var keep []bool
+		if onPath, _ := cmd.Flags().GetBool("on-path"); onPath {
+			keep = make([]bool, g.size())
+			if rq, ok := (pathQuery{from: "you", to: "out"}).resolve(g); ok {
+				keep = pathNodes(g, rq)
+			}
+		}
+
+		if err := exportGraph(os.Stdout, format, newReactorGraph(g, keep, counts)); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if cmd.Flags().Changed("from") || cmd.Flags().Changed("to") || cmd.Flags().Changed("via") || cmd.Flags().Changed("avoid") {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		via, _ := cmd.Flags().GetStringSlice("via")
+		avoid, _ := cmd.Flags().GetStringSlice("avoid")
+
+		q, err := newPathQuery(from, to, via, avoid)
+		if err != nil {
+			panic(err)
+		}
+
+		result, err := mode.format(mode.counter()(g, q))
+		if err != nil {
+			panic(err)
 		}
+		fmt.Println("Paths:", result)
+		return
 	}
 
-	am, _ := g.AdjacencyMap()
+	part1Result, err := mode.format(part1(g, mode.counter()))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Part 1:", part1Result)
 
-	fmt.Println("Part 1:", part1(am))
-	fmt.Println("Part 2:", part2(am))
+	part2Result, err := mode.format(part2(g, mode.counter()))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Part 2:", part2Result)
 }
 
-func part1(am map[string]map[string]graph.Edge[string]) int {
-	return countPaths(am, "you", "out")
+func part1(g *deviceGraph, count pathCounter) (int, error) {
+	return count(g, pathQuery{from: "you", to: "out"})
 }
 
-func part2(am map[string]map[string]graph.Edge[string]) int {
-	return countPaths(am, "svr", "fft") *
-		countPaths(am, "fft", "dac") *
-		countPaths(am, "dac", "out")
+// Part 2 counts paths from the server to the output that pass through both the fft and dac
+func part2(g *deviceGraph, count pathCounter) (int, error) {
+	return count(g, pathQuery{from: "svr", to: "out", via: []string{"fft", "dac"}})
 }
 
-func countPaths(am map[string]map[string]graph.Edge[string], start, end string) int {
-	edges := make(map[string]int)
-	reachable := make(map[string]bool)
-	reachable[start] = true
+// Count the paths matching the query by walking the devices in topological order
+// Each device keeps a count of paths reaching it for every subset of waypoints visited on the way.
+// Returns a cycleError if a cycle lies on one of the paths, since there would be infinitely many
+func countPaths(g *deviceGraph, q pathQuery) (int, error) {
+	rq, ok := q.resolve(g)
+	if !ok {
+		return 0, nil
+	}
 
-	queue := []string{start}
+	nodes := pathNodes(g, rq)
 
-	for len(queue) > 0 {
-		node := queue[0]
-		queue = queue[1:]
+	topo, err := topoOrder(g, nodes)
+	if err != nil {
+		return 0, err
+	}
 
-		for neighbor := range am[node] {
-			if !reachable[neighbor] {
-				reachable[neighbor] = true
-				queue = append(queue, neighbor)
+	if !nodes[rq.from] {
+		return 0, nil
+	}
+
+	// dp[node*subsets+visited] counts paths reaching node having visited that set of waypoints
+	subsets := q.allWaypoints() + 1
+	dp := make([]int, g.size()*subsets)
+	dp[rq.from*subsets+rq.bits[rq.from]] = 1
+
+	for _, node := range topo {
+		counts := dp[node*subsets : (node+1)*subsets]
+
+		for _, neighbor := range g.outputs(node) {
+			if !nodes[neighbor] {
+				continue
+			}
+
+			bit := rq.bits[neighbor]
+			for visited, cnt := range counts {
+				dp[neighbor*subsets+(visited|bit)] += cnt
 			}
-			edges[neighbor]++
 		}
 	}
 
-	dp := make(map[string]int)
-	dp[start] = 1
+	return dp[rq.to*subsets+q.allWaypoints()], nil
+}
+
+// Sort the devices so every device comes before the ones it outputs to (Kahn's algorithm)
+// Only edges between the given devices are followed. Returns a cycleError if they can't be sorted.
+func topoOrder(g *deviceGraph, nodes []bool) ([]int, error) {
+	edges := make([]int, g.size())
+	total := 0
 
-	topo := []string{}
-	queue = []string{}
+	for node, ok := range nodes {
+		if !ok {
+			continue
+		}
 
-	for node := range reachable {
-		if edges[node] == 0 {
+		total++
+		for _, neighbor := range g.outputs(node) {
+			if nodes[neighbor] {
+				edges[neighbor]++
+			}
+		}
+	}
+
+	topo := []int{}
+	queue := []int{}
+
+	for node, ok := range nodes {
+		if ok && edges[node] == 0 {
 			queue = append(queue, node)
 		}
 	}
@@ -85,8 +168,8 @@ func countPaths(am map[string]map[string]graph.Edge[string], start, end string)
 		queue = queue[1:]
 		topo = append(topo, node)
 
-		for neighbor := range am[node] {
-			if !reachable[neighbor] {
+		for _, neighbor := range g.outputs(node) {
+			if !nodes[neighbor] {
 				continue
 			}
 
@@ -97,15 +180,17 @@ func countPaths(am map[string]map[string]graph.Edge[string], start, end string)
 		}
 	}
 
-	for _, node := range topo {
-		for neighbor := range am[node] {
-			if reachable[neighbor] {
-				dp[neighbor] += dp[node]
-			}
+	// Anything left over is stuck behind a cycle
+	if len(topo) < total {
+		leftover := make([]bool, g.size())
+		for node, ok := range nodes {
+			leftover[node] = ok && edges[node] > 0
 		}
+
+		return nil, cycleError{findCycle(g, leftover)}
 	}
 
-	return dp[end]
+	return topo, nil
 }
 
 func parseInput(input string) []device {
@@ -116,7 +201,7 @@ func parseInput(input string) []device {
 		line = strings.TrimSpace(line)
 		parts := strings.Split(line, ": ")
 		label := parts[0]
-		outputs := strings.Split(parts[1], " ")
+		outputs := strings.Fields(parts[1])
 
 		d := device{
 			label,
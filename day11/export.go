@@ -0,0 +1,178 @@
+package day11
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Count the paths from `from` to `to` passing through each device on one of them
+// Paths reaching a device times paths leaving it to the end gives the paths through it
+func nodePathCounts(g *deviceGraph, from, to string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	rq, ok := (pathQuery{from: from, to: to}).resolve(g)
+	if !ok {
+		return counts, nil
+	}
+
+	nodes := pathNodes(g, rq)
+
+	topo, err := topoOrder(g, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	reaching := make([]int, g.size())
+	leaving := make([]int, g.size())
+	if nodes[rq.from] {
+		reaching[rq.from] = 1
+		leaving[rq.to] = 1
+	}
+
+	for _, node := range topo {
+		for _, neighbor := range g.outputs(node) {
+			if nodes[neighbor] {
+				reaching[neighbor] += reaching[node]
+			}
+		}
+	}
+
+	for _, node := range slices.Backward(topo) {
+		for _, neighbor := range g.outputs(node) {
+			if nodes[neighbor] {
+				leaving[node] += leaving[neighbor]
+			}
+		}
+	}
+
+	for node, ok := range nodes {
+		if ok {
+			counts[g.labels[node]] = reaching[node] * leaving[node]
+		}
+	}
+
+	return counts, nil
+}
+
+// Devices and connections to export, in a stable order
+// `counts` holds the paths through each device, or nil if there's a cycle and they can't be counted
+type reactorGraph struct {
+	nodes   []string
+	outputs map[string][]string
+	counts  map[string]int
+}
+
+// Collect the devices to export, keeping only those in `keep` if it's not nil
+func newReactorGraph(g *deviceGraph, keep []bool, counts map[string]int) reactorGraph {
+	rg := reactorGraph{outputs: make(map[string][]string), counts: counts}
+
+	ids := make([]int, g.size())
+	for id := range ids {
+		ids[id] = id
+	}
+	slices.SortFunc(ids, func(a, b int) int {
+		return strings.Compare(g.labels[a], g.labels[b])
+	})
+
+	for _, id := range ids {
+		if keep != nil && !keep[id] {
+			continue
+		}
+
+		node := g.labels[id]
+		rg.nodes = append(rg.nodes, node)
+		rg.outputs[node] = []string{}
+
+		for _, neighbor := range g.outputs(id) {
+			if keep == nil || keep[neighbor] {
+				rg.outputs[node] = append(rg.outputs[node], g.labels[neighbor])
+			}
+		}
+		slices.Sort(rg.outputs[node])
+	}
+
+	return rg
+}
+
+func (rg reactorGraph) label(node string) string {
+	if cnt, ok := rg.counts[node]; ok {
+		return fmt.Sprintf("%s (%d)", node, cnt)
+	}
+
+	return node
+}
+
+// Write the device graph in the given format (dot, mermaid or json)
+func exportGraph(out io.Writer, format string, rg reactorGraph) error {
+	switch format {
+	case "dot":
+		return exportGraphDot(out, rg)
+	case "mermaid":
+		return exportGraphMermaid(out, rg)
+	case "json":
+		return exportGraphJson(out, rg)
+	}
+
+	return fmt.Errorf("unknown export format %q, expected dot, mermaid or json", format)
+}
+
+func exportGraphDot(out io.Writer, rg reactorGraph) error {
+	fmt.Fprintln(out, "digraph reactor {")
+
+	for _, node := range rg.nodes {
+		fmt.Fprintf(out, "  %q [label=%q];\n", node, rg.label(node))
+	}
+
+	for _, node := range rg.nodes {
+		for _, neighbor := range rg.outputs[node] {
+			fmt.Fprintf(out, "  %q -> %q;\n", node, neighbor)
+		}
+	}
+
+	_, err := fmt.Fprintln(out, "}")
+	return err
+}
+
+func exportGraphMermaid(out io.Writer, rg reactorGraph) error {
+	fmt.Fprintln(out, "flowchart LR")
+
+	// Mermaid ids can't contain arbitrary characters, so number the devices and put names in labels
+	ids := make(map[string]string)
+	for i, node := range rg.nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(out, "  %s[\"%s\"]\n", ids[node], rg.label(node))
+	}
+
+	for _, node := range rg.nodes {
+		for _, neighbor := range rg.outputs[node] {
+			fmt.Fprintf(out, "  %s --> %s\n", ids[node], ids[neighbor])
+		}
+	}
+
+	return nil
+}
+
+type jsonNode struct {
+	Id      string   `json:"id"`
+	Paths   *int     `json:"paths,omitempty"`
+	Outputs []string `json:"outputs"`
+}
+
+func exportGraphJson(out io.Writer, rg reactorGraph) error {
+	nodes := []jsonNode{}
+	for _, node := range rg.nodes {
+		n := jsonNode{Id: node, Outputs: rg.outputs[node]}
+		if cnt, ok := rg.counts[node]; ok {
+			n.Paths = &cnt
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nodes)
+}
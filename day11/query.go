@@ -0,0 +1,81 @@
+package day11
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Most waypoints a query can have, since every device keeps a count for each subset of them
+const maxWaypoints = 16
+
+// Paths to count between two devices
+// Paths must pass through every device in `via`, in any order, and never touch a device in `avoid`
+type pathQuery struct {
+	from, to string
+	via      []string
+	avoid    []string
+}
+
+func newPathQuery(from, to string, via, avoid []string) (pathQuery, error) {
+	q := pathQuery{from, to, []string{}, slices.Clone(avoid)}
+
+	for _, node := range via {
+		if !slices.Contains(q.via, node) {
+			q.via = append(q.via, node)
+		}
+	}
+
+	if len(q.via) > maxWaypoints {
+		return pathQuery{}, fmt.Errorf("too many waypoints (%d), at most %d are supported", len(q.via), maxWaypoints)
+	}
+
+	for _, node := range q.via {
+		if slices.Contains(q.avoid, node) {
+			return pathQuery{}, fmt.Errorf("%s can't be both visited and avoided", node)
+		}
+	}
+
+	return q, nil
+}
+
+// Query with its devices looked up in a graph
+// `bits` gives each device's bit in the set of visited waypoints, or 0 if it isn't a waypoint
+type resolvedQuery struct {
+	from, to int
+	bits     []int
+	avoid    []bool
+}
+
+// Look up the query's devices in the graph
+// Returns false if the start, end or a waypoint isn't in the graph, since then no path can match
+func (q pathQuery) resolve(g *deviceGraph) (resolvedQuery, bool) {
+	rq := resolvedQuery{bits: make([]int, g.size()), avoid: make([]bool, g.size())}
+
+	var okFrom, okTo bool
+	rq.from, okFrom = g.id(q.from)
+	rq.to, okTo = g.id(q.to)
+	if !okFrom || !okTo {
+		return rq, false
+	}
+
+	for i, node := range q.via {
+		id, ok := g.id(node)
+		if !ok {
+			return rq, false
+		}
+		rq.bits[id] = 1 << i
+	}
+
+	for _, node := range q.avoid {
+		if id, ok := g.id(node); ok {
+			rq.avoid[id] = true
+		}
+	}
+
+	return rq, true
+}
+
+// Set of visited waypoints once every one has been visited
+func (q pathQuery) allWaypoints() int {
+	return 1<<len(q.via) - 1
+}
@@ -10,6 +10,17 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type xy struct {
+	x, y int
+}
+
+// Present shape, made up of the cells marked with # in the input
+type shape struct {
+	index         int
+	cells         []xy
+	width, height int
+}
+
 type region struct {
 	width, height int
 	quantities    []int
@@ -21,7 +32,14 @@ func Run(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
-	regions := parseInput(string(contents))
+	shapes, regions, err := parseInput(string(contents))
+	if err != nil {
+		panic(err)
+	}
+
+	if report, _ := cmd.Flags().GetBool("report"); report {
+		printReport(os.Stdout, shapes, regions)
+	}
 
 	fmt.Println("Part 1:", part1(regions))
 }
@@ -45,14 +63,56 @@ func part1(regions []region) int {
 	return total
 }
 
-func parseInput(input string) []region {
+// Parse input into shapes and regions
+// Shapes are blocks starting with an `index:` line followed by rows of # and .
+func parseInput(input string) ([]shape, []region, error) {
 	lines := strings.Split(strings.TrimSpace(input), "\n")
+	shapes := []shape{}
 	regions := []region{}
 
+	shapeRx := regexp.MustCompile(`^(\d+):$`)
 	lineRx := regexp.MustCompile(`(\d+)x(\d+): ([0-9 ]+)`)
 
-	for _, line := range lines {
+	var current *shape
+	row := 0
+
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
+
+		if line == "" {
+			current = nil
+			continue
+		}
+
+		if matches := shapeRx.FindStringSubmatch(line); matches != nil {
+			index, _ := strconv.Atoi(matches[1])
+			if index != len(shapes) {
+				return nil, nil, fmt.Errorf("line %d: expected shape %d but got %d", i+1, len(shapes), index)
+			}
+
+			shapes = append(shapes, shape{index: index})
+			current = &shapes[len(shapes)-1]
+			row = 0
+			continue
+		}
+
+		if current != nil {
+			for x, c := range line {
+				switch c {
+				case '#':
+					current.cells = append(current.cells, xy{x, row})
+				case '.':
+				default:
+					return nil, nil, fmt.Errorf("line %d: shape %d has %q, expected # or .", i+1, current.index, c)
+				}
+			}
+
+			current.width = max(current.width, len(line))
+			current.height = row + 1
+			row++
+			continue
+		}
+
 		matches := lineRx.FindStringSubmatch(line)
 
 		if len(matches) == 0 {
@@ -63,12 +123,16 @@ func parseInput(input string) []region {
 		height, _ := strconv.Atoi(matches[2])
 		quantities := []int{}
 
-		splits := strings.Split(matches[3], " ")
+		splits := strings.Fields(matches[3])
 		for _, split := range splits {
 			v, _ := strconv.Atoi(split)
 			quantities = append(quantities, v)
 		}
 
+		if len(shapes) > 0 && len(quantities) > len(shapes) {
+			return nil, nil, fmt.Errorf("line %d: %d quantities given but only %d shapes", i+1, len(quantities), len(shapes))
+		}
+
 		region := region{
 			width,
 			height,
@@ -78,5 +142,5 @@ func parseInput(input string) []region {
 		regions = append(regions, region)
 	}
 
-	return regions
+	return shapes, regions, nil
 }
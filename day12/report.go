@@ -0,0 +1,98 @@
+package day12
+
+import (
+	"fmt"
+	"io"
+)
+
+// How sure we can be that a region's presents fit without searching for a packing
+type verdict int
+
+const (
+	// Every present fits in its own slot of the region
+	fitsDefinitely verdict = iota
+	// The presents have more cells than the region
+	fitsNever
+	// Somewhere in between, so only trying packings would tell
+	fitsUnknown
+)
+
+func (v verdict) String() string {
+	switch v {
+	case fitsDefinitely:
+		return "definitely fits"
+	case fitsNever:
+		return "definitely doesn't"
+	}
+
+	return "needs search"
+}
+
+// Bounds on whether a region's presents fit
+// `cells` is how many cells the presents cover, which can't exceed the region's area.
+// `slots` is how many non-overlapping boxes the size of the largest shape fit in the region,
+// and if there's one for every present they can't get in each other's way.
+type regionBounds struct {
+	area, cells     int
+	slots, presents int
+	verdict         verdict
+}
+
+func bounds(shapes []shape, r region) regionBounds {
+	// Without any shapes to go on, assume every present fills a full 3x3 box
+	slotWidth, slotHeight := 3, 3
+	if len(shapes) > 0 {
+		slotWidth, slotHeight = 1, 1
+		for _, s := range shapes {
+			slotWidth = max(slotWidth, s.width)
+			slotHeight = max(slotHeight, s.height)
+		}
+	}
+
+	b := regionBounds{
+		area:  r.width * r.height,
+		slots: (r.width / slotWidth) * (r.height / slotHeight),
+	}
+
+	for i, q := range r.quantities {
+		b.presents += q
+		if i < len(shapes) {
+			b.cells += q * len(shapes[i].cells)
+		} else {
+			b.cells += q * slotWidth * slotHeight
+		}
+	}
+
+	switch {
+	case b.cells > b.area:
+		b.verdict = fitsNever
+	case b.presents <= b.slots:
+		b.verdict = fitsDefinitely
+	default:
+		b.verdict = fitsUnknown
+	}
+
+	return b
+}
+
+// Print how each region is classified by the cell and slot bounds, then a summary
+func printReport(out io.Writer, shapes []shape, regions []region) {
+	counts := make(map[verdict]int)
+
+	for i, r := range regions {
+		b := bounds(shapes, r)
+		counts[b.verdict]++
+
+		fmt.Fprintf(out, "Region %d (%dx%d): %d/%d cells, %d presents in %d slots: %s\n",
+			i+1, r.width, r.height, b.cells, b.area, b.presents, b.slots, b.verdict)
+	}
+
+	fmt.Fprintf(out, "Definitely fit: %d, definitely don't: %d, need search: %d\n",
+		counts[fitsDefinitely], counts[fitsNever], counts[fitsUnknown])
+
+	if counts[fitsUnknown] == 0 {
+		fmt.Fprintln(out, "Every region is settled by the bounds, so the answer is exact")
+	} else {
+		fmt.Fprintf(out, "Answer is between %d and %d\n", counts[fitsDefinitely], counts[fitsDefinitely]+counts[fitsUnknown])
+	}
+}
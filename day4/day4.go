@@ -2,6 +2,7 @@ package day4
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 
@@ -51,6 +52,24 @@ func Run(cmd *cobra.Command, args []string) {
 
 	grid, width, height := parseInput(string(contents))
 
+	visualize, _ := cmd.Flags().GetBool("visualize")
+	gifPath, _ := cmd.Flags().GetString("gif")
+	delay, _ := cmd.Flags().GetInt("delay")
+
+	if visualize || gifPath != "" {
+		waves := removalWaves(maps.Clone(grid), width, height)
+
+		if visualize {
+			renderWaves(os.Stdout, grid, waves, width, height, delay)
+		}
+
+		if gifPath != "" {
+			if err := exportGif(gifPath, grid, waves, width, height, delay); err != nil {
+				panic(err)
+			}
+		}
+	}
+
 	part1Result := part1(grid, width, height)
 	part2Result := part2(grid, width, height)
 
@@ -79,30 +98,36 @@ func part2(grid map[point]node, width int, height int) int {
 	for {
 		removed := removeAccessible(grid, width, height)
 
-		if removed == 0 {
+		if len(removed) == 0 {
 			break
 		}
 
-		count += removed
+		count += len(removed)
 	}
 
 	return count
 }
 
-func removeAccessible(grid map[point]node, width int, height int) int {
-	removed := 0
+// Remove all paper that is accessible at the start of the wave
+// Everything is checked before anything is removed, so paper only becomes accessible in the next wave
+// Returns the points that were removed during the wave
+func removeAccessible(grid map[point]node, width int, height int) []point {
+	removed := []point{}
 
 	for y := range height {
 		for x := range width {
 			pt := point{x, y}
 
 			if accessible(grid, pt) {
-				grid[pt] = empty
-				removed += 1
+				removed = append(removed, pt)
 			}
 		}
 	}
 
+	for _, pt := range removed {
+		grid[pt] = empty
+	}
+
 	return removed
 }
 
@@ -0,0 +1,167 @@
+package day4
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	ansiReset   = "\033[0m"
+	ansiRed     = "\033[1;31m"
+	ansiDim     = "\033[2m"
+	ansiClear   = "\033[H\033[2J"
+	gifCellSize = 4
+
+	// GIF frame delay when none is given, in hundredths of a second
+	gifDefaultDelay = 50
+)
+
+// Palette indexes used for GIF frames
+const (
+	gifEmpty uint8 = iota
+	gifPaper
+	gifRemoved
+	gifCleared
+)
+
+var gifPalette = color.Palette{
+	color.RGBA{0x10, 0x10, 0x18, 0xff}, // empty floor
+	color.RGBA{0xe8, 0xe8, 0xe0, 0xff}, // paper
+	color.RGBA{0xe0, 0x30, 0x30, 0xff}, // removed in this wave
+	color.RGBA{0x50, 0x50, 0x60, 0xff}, // removed in an earlier wave
+}
+
+// Remove accessible paper until none remains, recording the points removed in each wave
+func removalWaves(grid map[point]node, width int, height int) [][]point {
+	waves := [][]point{}
+
+	for {
+		removed := removeAccessible(grid, width, height)
+
+		if len(removed) == 0 {
+			break
+		}
+
+		waves = append(waves, removed)
+	}
+
+	return waves
+}
+
+// Print each wave to the terminal, highlighting the paper removed in that wave
+// If a delay is given, each frame replaces the previous one to animate the removal
+func renderWaves(out io.Writer, grid map[point]node, waves [][]point, width int, height int, delay int) {
+	cleared := make(map[point]bool)
+
+	for i, wave := range waves {
+		removed := make(map[point]bool, len(wave))
+		for _, pt := range wave {
+			removed[pt] = true
+		}
+
+		var sb strings.Builder
+		if delay > 0 {
+			sb.WriteString(ansiClear)
+		}
+
+		fmt.Fprintf(&sb, "Wave %d: %d removed\n", i+1, len(wave))
+
+		for y := range height {
+			for x := range width {
+				pt := point{x, y}
+
+				switch {
+				case removed[pt]:
+					sb.WriteString(ansiRed + "@" + ansiReset)
+				case cleared[pt]:
+					sb.WriteString(ansiDim + "x" + ansiReset)
+				case grid[pt] == paper:
+					sb.WriteString("@")
+				default:
+					sb.WriteString(".")
+				}
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+
+		fmt.Fprint(out, sb.String())
+
+		if delay > 0 {
+			time.Sleep(time.Duration(delay) * time.Millisecond)
+		}
+
+		for pt := range removed {
+			cleared[pt] = true
+		}
+	}
+}
+
+// Export the removal waves as an animated GIF with one frame per wave
+func exportGif(path string, grid map[point]node, waves [][]point, width int, height int, delay int) error {
+	// GIF delays are in hundredths of a second
+	frameDelay := gifDefaultDelay
+	if delay > 0 {
+		frameDelay = max(delay/10, 1)
+	}
+
+	bounds := image.Rect(0, 0, width*gifCellSize, height*gifCellSize)
+	anim := gif.GIF{}
+
+	frame := image.NewPaletted(bounds, gifPalette)
+	for y := range height {
+		for x := range width {
+			if grid[point{x, y}] == paper {
+				fillCell(frame, point{x, y}, gifPaper)
+			} else {
+				fillCell(frame, point{x, y}, gifEmpty)
+			}
+		}
+	}
+
+	// Initial frame shows the untouched floor
+	anim.Image = append(anim.Image, frame)
+	anim.Delay = append(anim.Delay, frameDelay)
+
+	for _, wave := range waves {
+		next := image.NewPaletted(bounds, gifPalette)
+		copy(next.Pix, frame.Pix)
+
+		// Paper removed in the previous wave is shown as cleared
+		for i, idx := range next.Pix {
+			if idx == gifRemoved {
+				next.Pix[i] = gifCleared
+			}
+		}
+
+		for _, pt := range wave {
+			fillCell(next, pt, gifRemoved)
+		}
+
+		anim.Image = append(anim.Image, next)
+		anim.Delay = append(anim.Delay, frameDelay)
+		frame = next
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gif.EncodeAll(f, &anim)
+}
+
+func fillCell(img *image.Paletted, pt point, idx uint8) {
+	for dy := range gifCellSize {
+		for dx := range gifCellSize {
+			img.SetColorIndex(pt.x*gifCellSize+dx, pt.y*gifCellSize+dy, idx)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package day5
+
+import (
+	"math/big"
+	"slices"
+)
+
+// Part 1 using arbitrary-precision IDs
+func bigPart1(freshProducts []bigRange, availableProducts []*big.Int) int {
+	total := 0
+
+	for _, product := range availableProducts {
+		for _, freshRange := range freshProducts {
+			if product.Cmp(freshRange.min) >= 0 && product.Cmp(freshRange.max) <= 0 {
+				total += 1
+				break
+			}
+		}
+	}
+
+	return total
+}
+
+// Part 2 using arbitrary-precision IDs
+// Ranges are sorted by their start and merged in a single pass
+func bigPart2(freshProducts []bigRange) *big.Int {
+	sorted := slices.Clone(freshProducts)
+	slices.SortFunc(sorted, func(a, b bigRange) int {
+		return a.min.Cmp(b.min)
+	})
+
+	total := new(big.Int)
+	one := big.NewInt(1)
+
+	var curMin, curMax *big.Int
+	for _, r := range sorted {
+		if curMax != nil && r.min.Cmp(curMax) <= 0 {
+			if r.max.Cmp(curMax) > 0 {
+				curMax = r.max
+			}
+			continue
+		}
+
+		if curMax != nil {
+			total.Add(total, new(big.Int).Sub(curMax, curMin))
+			total.Add(total, one)
+		}
+
+		curMin, curMax = r.min, r.max
+	}
+
+	if curMax != nil {
+		total.Add(total, new(big.Int).Sub(curMax, curMin))
+		total.Add(total, one)
+	}
+
+	return total
+}
@@ -1,15 +1,18 @@
 package day5
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
-	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
 
-type productId int
+type productId int64
+
+var errTooManyFresh = errors.New("fresh product count does not fit in 64 bits")
 
 type productRange struct {
 	min productId
@@ -20,29 +23,62 @@ func (r productRange) Contains(value productId) bool {
 	return value >= r.min && value <= r.max
 }
 
-func (r productRange) FitsIn(other productRange) bool {
-	return r.min >= other.min && r.max <= other.max
-}
+func Run(cmd *cobra.Command, args []string) {
 
-func (r productRange) IntersectsStartOf(other productRange) bool {
-	return r.min < other.min && r.max >= other.min
-}
+	contents, err := os.ReadFile(args[0])
+	if err != nil {
+		panic(err)
+	}
 
-func (r productRange) IntersectsEndOf(other productRange) bool {
-	return r.min < other.max && r.max >= other.max
-}
+	serve, _ := cmd.Flags().GetString("serve")
 
-func Run(cmd *cobra.Command, args []string) {
+	if useBig, _ := cmd.Flags().GetBool("big"); useBig {
+		if serve != "" {
+			panic("--big can't be combined with --serve")
+		}
 
-	contents, err := os.ReadFile(args[0])
+		freshProducts, availableProducts, err := parseBigInput(string(contents))
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println("Part 1:", bigPart1(freshProducts, availableProducts))
+		fmt.Println("Part 2:", bigPart2(freshProducts))
+		return
+	}
+
+	freshProducts, availableProducts, err := parseInput(string(contents))
 	if err != nil {
 		panic(err)
 	}
 
-	freshProducts, availableProducts := parseInput(string(contents))
+	switch serve {
+	case "":
+	case "stdin":
+		if err := serveLines(newFreshIndex(freshProducts), os.Stdin, os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	case "http":
+		addr, _ := cmd.Flags().GetString("addr")
+		if err := checkLocalAddr(addr); err != nil {
+			panic(err)
+		}
+
+		fmt.Println("Listening on", addr)
+		if err := http.ListenAndServe(addr, newFreshIndex(freshProducts).handler()); err != nil {
+			panic(err)
+		}
+		return
+	default:
+		panic(fmt.Sprintf("unknown serve mode %q", serve))
+	}
 
 	part1Result := part1(freshProducts, availableProducts)
-	part2Result := part2(freshProducts)
+	part2Result, err := part2(freshProducts)
+	if err != nil {
+		panic(err)
+	}
 
 	fmt.Println("Part 1:", part1Result)
 	fmt.Println("Part 2:", part2Result)
@@ -68,86 +104,27 @@ func part1(freshProducts []productRange, availableProducts []productId) int {
 	return total
 }
 
-func part2(freshProducts []productRange) int {
-	total := 0
-	adjusting := true
-
-	for adjusting {
-		freshProducts, adjusting = part2AdjustRanges(freshProducts)
-	}
-
-	for _, productRange := range freshProducts {
-		total += (int(productRange.max) - int(productRange.min)) + 1
+func part2(freshProducts []productRange) (int, error) {
+	total, err := countProducts(mergeRanges(freshProducts))
+	if err != nil {
+		return 0, fmt.Errorf("%w, try --big", err)
 	}
 
-	return total
+	return total, nil
 }
 
-func part2AdjustRanges(productRanges []productRange) ([]productRange, bool) {
-	adjustedRanges := []productRange{}
-	adjusted := false
+// Count the products in ranges that don't overlap, failing if the count doesn't fit in an int
+func countProducts(productRanges []productRange) (int, error) {
+	total := 0
 
 	for _, productRange := range productRanges {
-		for _, otherProductRange := range productRanges {
-			if productRange == otherProductRange {
-				continue
-			}
-
-			if productRange.FitsIn(otherProductRange) {
-				adjusted = true
-				goto skip
-			}
-
-			if productRange.IntersectsStartOf(otherProductRange) {
-				productRange.max = max(productRange.max, otherProductRange.max)
-				adjusted = true
-			}
-
-			if productRange.IntersectsEndOf(otherProductRange) {
-				productRange.min = min(productRange.min, otherProductRange.min)
-				adjusted = true
-			}
-		}
-
-		adjustedRanges = append(adjustedRanges, productRange)
-	skip:
-	}
-
-	return uniqueRanges(adjustedRanges), adjusted
-}
-
-func uniqueRanges(productRanges []productRange) []productRange {
-	seen := make(map[productRange]bool)
-	uniques := []productRange{}
-
-	for _, item := range productRanges {
-		if _, ok := seen[item]; !ok {
-			uniques = append(uniques, item)
-			seen[item] = true
+		size := int(productRange.max - productRange.min)
+		if size == math.MaxInt || total > math.MaxInt-size-1 {
+			return 0, errTooManyFresh
 		}
-	}
 
-	return uniques
-}
-
-func parseInput(input string) ([]productRange, []productId) {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
-	freshProducts := []productRange{}
-	availableProducts := []productId{}
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.Contains(line, "-") {
-			parts := strings.Split(line, "-")
-			min, _ := strconv.Atoi(parts[0])
-			max, _ := strconv.Atoi(parts[1])
-			freshProducts = append(freshProducts, productRange{productId(min), productId(max)})
-		} else if line != "" {
-			val, _ := strconv.Atoi(line)
-			availableProducts = append(availableProducts, productId(val))
-		}
+		total += size + 1
 	}
 
-	return freshProducts, availableProducts
+	return total, nil
 }
@@ -0,0 +1,217 @@
+package day5
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Error found while parsing the input, including the 1-based line number it occurred on
+type parseError struct {
+	line int
+	text string
+	err  error
+}
+
+func (e parseError) Error() string {
+	return fmt.Sprintf("line %d (%q): %v", e.line, e.text, e.err)
+}
+
+func (e parseError) Unwrap() error {
+	return e.err
+}
+
+var (
+	errNoSeparator = errors.New("missing blank line between ranges and IDs")
+	errNoRanges    = errors.New("ranges section is empty")
+	errBadRange    = errors.New("range must be formatted as min-max")
+	errBadNumber   = errors.New("value must be a non-negative integer")
+	errOutOfRange  = errors.New("value does not fit in 64 bits")
+	errMinOverMax  = errors.New("range min is greater than max")
+	errExtraBlank  = errors.New("unexpected blank line in IDs section")
+)
+
+// Walk the two sections of the input
+// The input must contain the ranges section, a single blank line, then the IDs section, which may be empty
+// `rangeFn` is called with the min and max text of each range, `idFn` with the text of each ID
+func parseSections(input string, rangeFn func(lo, hi string) error, idFn func(id string) error) error {
+	// Only the final line break is dropped, so a blank line at the end can still be the separator
+	lines := strings.Split(strings.TrimSuffix(input, "\n"), "\n")
+	inRanges := true
+	numRanges := 0
+
+	// Blank lines trailing the IDs are fine, so one is only reported once another ID follows it
+	blankLine := 0
+
+	for i, raw := range lines {
+		lineNum := i + 1
+		line := strings.TrimSpace(raw)
+
+		if line == "" {
+			if !inRanges {
+				if blankLine == 0 {
+					blankLine = lineNum
+				}
+				continue
+			}
+
+			if numRanges == 0 {
+				return parseError{lineNum, line, errNoRanges}
+			}
+
+			inRanges = false
+			continue
+		}
+
+		if inRanges {
+			lo, hi, found := strings.Cut(line, "-")
+			if !found {
+				// A bare number here usually means the separator is missing
+				if isDigits(line) {
+					return parseError{lineNum, line, errNoSeparator}
+				}
+
+				return parseError{lineNum, line, errBadRange}
+			}
+
+			if err := rangeFn(lo, hi); err != nil {
+				return parseError{lineNum, line, err}
+			}
+
+			numRanges++
+		} else {
+			if blankLine > 0 {
+				return parseError{blankLine, "", errExtraBlank}
+			}
+
+			if err := idFn(line); err != nil {
+				return parseError{lineNum, line, err}
+			}
+		}
+	}
+
+	if inRanges {
+		return parseError{len(lines), strings.TrimSpace(lines[len(lines)-1]), errNoSeparator}
+	}
+
+	return nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse a product ID as a 64-bit integer
+// Signs are rejected so negative IDs aren't mistaken for ranges
+func parseProductId(s string) (productId, error) {
+	if !isDigits(s) {
+		return 0, errBadNumber
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errOutOfRange
+	}
+
+	return productId(v), nil
+}
+
+// Parse input file into fresh ranges and available products
+func parseInput(input string) ([]productRange, []productId, error) {
+	freshProducts := []productRange{}
+	availableProducts := []productId{}
+
+	err := parseSections(input,
+		func(lo, hi string) error {
+			min, err := parseProductId(lo)
+			if err != nil {
+				return err
+			}
+
+			max, err := parseProductId(hi)
+			if err != nil {
+				return err
+			}
+
+			if min > max {
+				return errMinOverMax
+			}
+
+			freshProducts = append(freshProducts, productRange{min, max})
+			return nil
+		},
+		func(id string) error {
+			v, err := parseProductId(id)
+			if err != nil {
+				return err
+			}
+
+			availableProducts = append(availableProducts, v)
+			return nil
+		})
+
+	return freshProducts, availableProducts, err
+}
+
+// Arbitrary-precision equivalent of productRange
+type bigRange struct {
+	min, max *big.Int
+}
+
+func parseBigProductId(s string) (*big.Int, error) {
+	if !isDigits(s) {
+		return nil, errBadNumber
+	}
+
+	v, _ := new(big.Int).SetString(s, 10)
+	return v, nil
+}
+
+// Parse input file using arbitrary-precision IDs
+func parseBigInput(input string) ([]bigRange, []*big.Int, error) {
+	freshProducts := []bigRange{}
+	availableProducts := []*big.Int{}
+
+	err := parseSections(input,
+		func(lo, hi string) error {
+			min, err := parseBigProductId(lo)
+			if err != nil {
+				return err
+			}
+
+			max, err := parseBigProductId(hi)
+			if err != nil {
+				return err
+			}
+
+			if min.Cmp(max) > 0 {
+				return errMinOverMax
+			}
+
+			freshProducts = append(freshProducts, bigRange{min, max})
+			return nil
+		},
+		func(id string) error {
+			v, err := parseBigProductId(id)
+			if err != nil {
+				return err
+			}
+
+			availableProducts = append(availableProducts, v)
+			return nil
+		})
+
+	return freshProducts, availableProducts, err
+}
@@ -0,0 +1,223 @@
+package day5
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Normalized set of fresh ranges used to answer freshness queries
+type freshIndex struct {
+	ranges []productRange
+}
+
+type freshResult struct {
+	Id    productId `json:"id"`
+	Fresh bool      `json:"fresh"`
+}
+
+type coverageRange struct {
+	Min productId `json:"min"`
+	Max productId `json:"max"`
+}
+
+type coverageResult struct {
+	Ranges []coverageRange `json:"ranges"`
+	Total  int             `json:"total"`
+}
+
+// Sort and merge overlapping or adjacent ranges
+func mergeRanges(productRanges []productRange) []productRange {
+	sorted := slices.Clone(productRanges)
+	slices.SortFunc(sorted, func(a, b productRange) int {
+		if a.min != b.min {
+			return cmp.Compare(a.min, b.min)
+		}
+		return cmp.Compare(a.max, b.max)
+	})
+
+	merged := []productRange{}
+	for _, r := range sorted {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			// IDs are never negative, so unlike last.max+1 this can't wrap around
+			if r.min-1 <= last.max {
+				last.max = max(last.max, r.max)
+				continue
+			}
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// Check that an address to listen on is only reachable from this machine
+func checkLocalAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("address %q is not a loopback address, the service only runs locally", addr)
+	}
+
+	return nil
+}
+
+func newFreshIndex(freshProducts []productRange) freshIndex {
+	return freshIndex{mergeRanges(freshProducts)}
+}
+
+// Check if a product is fresh using a binary search over the merged ranges
+func (idx freshIndex) isFresh(id productId) bool {
+	i := sort.Search(len(idx.ranges), func(i int) bool {
+		return idx.ranges[i].max >= id
+	})
+
+	return i < len(idx.ranges) && idx.ranges[i].Contains(id)
+}
+
+func (idx freshIndex) lookup(ids []productId) []freshResult {
+	results := make([]freshResult, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, freshResult{id, idx.isFresh(id)})
+	}
+
+	return results
+}
+
+func (idx freshIndex) coverage() (coverageResult, error) {
+	total, err := countProducts(idx.ranges)
+	if err != nil {
+		return coverageResult{}, err
+	}
+
+	result := coverageResult{Ranges: []coverageRange{}, Total: total}
+	for _, r := range idx.ranges {
+		result.Ranges = append(result.Ranges, coverageRange{r.min, r.max})
+	}
+
+	return result, nil
+}
+
+// Parse a list of product IDs separated by whitespace or commas
+func parseIds(input string) ([]productId, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	ids := make([]productId, 0, len(fields))
+	for _, field := range fields {
+		v, err := parseProductId(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID %q: %w", field, err)
+		}
+		ids = append(ids, v)
+	}
+
+	return ids, nil
+}
+
+// Answer queries read line by line from `in`
+// Each line is either a list of product IDs or the `coverage` command
+func serveLines(idx freshIndex, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch line {
+		case "":
+			continue
+		case "coverage":
+			coverage, err := idx.coverage()
+			if err != nil {
+				fmt.Fprintln(out, "error", err)
+				continue
+			}
+
+			for _, r := range coverage.Ranges {
+				fmt.Fprintf(out, "%d-%d\n", r.Min, r.Max)
+			}
+			fmt.Fprintf(out, "total %d\n", coverage.Total)
+		default:
+			ids, err := parseIds(line)
+			if err != nil {
+				fmt.Fprintln(out, "error", err)
+				continue
+			}
+
+			for _, result := range idx.lookup(ids) {
+				if result.Fresh {
+					fmt.Fprintln(out, result.Id, "fresh")
+				} else {
+					fmt.Fprintln(out, result.Id, "spoiled")
+				}
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// HTTP handlers for freshness queries
+//
+//	GET  /fresh?id=1&id=2  look up one or more IDs
+//	POST /fresh            look up IDs listed in the request body
+//	GET  /coverage         merged ranges and total fresh count
+func (idx freshIndex) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /fresh", func(w http.ResponseWriter, r *http.Request) {
+		ids, err := parseIds(strings.Join(r.URL.Query()["id"], ","))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writeJson(w, idx.lookup(ids))
+	})
+
+	mux.HandleFunc("POST /fresh", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ids, err := parseIds(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writeJson(w, idx.lookup(ids))
+	})
+
+	mux.HandleFunc("GET /coverage", func(w http.ResponseWriter, r *http.Request) {
+		coverage, err := idx.coverage()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJson(w, coverage)
+	})
+
+	return mux
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -1,7 +1,9 @@
 package day6
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"slices"
@@ -14,33 +16,37 @@ import (
 type problem struct {
 	operation operation
 	operands  []int
-}
-
-type operation int
 
-const (
-	addition operation = iota
-	multiplication
-)
+	// Span of worksheet columns the problem occupies, end is exclusive
+	start, end int
+}
 
-func (p problem) solve() int {
+func (p problem) solve() (int, error) {
 	var result int
 
+	apply, ok := operators[p.operation]
+	if !ok {
+		if p.operation == "" {
+			return 0, errNoOperator
+		}
+		return 0, fmt.Errorf("unknown operator %q", p.operation)
+	}
+
 	// Perform the operation on all operands
 	// First operand is used as the seed value
 	for i, operand := range p.operands {
 		if i == 0 {
 			result = operand
 		} else {
-			if p.operation == addition {
-				result += operand
-			} else {
-				result *= operand
+			var err error
+			result, err = apply(result, operand)
+			if err != nil {
+				return 0, fmt.Errorf("columns %d-%d: %w", p.start, p.end-1, err)
 			}
 		}
 	}
 
-	return result
+	return result, nil
 }
 
 func Run(cmd *cobra.Command, args []string) {
@@ -49,141 +55,324 @@ func Run(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
-	part1Problems := part1ParseInput(string(contents))
-	fmt.Println("Part 1:", part1(part1Problems))
+	explain, _ := cmd.Flags().GetBool("explain")
+
+	part1Problems, err := part1ParseInput(string(contents))
+	if err != nil {
+		panic(err)
+	}
+	if explain {
+		explainProblems(os.Stdout, part1Problems)
+	}
+	part1Result, err := part1(part1Problems)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Part 1:", part1Result)
+
+	directionFlag, _ := cmd.Flags().GetString("direction")
+	direction, err := parseReadDirection(directionFlag)
+	if err != nil {
+		panic(err)
+	}
+
+	part2Problems, err := part2ParseInput(string(contents), direction)
+	if err != nil {
+		panic(err)
+	}
+	if explain {
+		explainProblems(os.Stdout, part2Problems)
+	}
+	part2Result, err := part1(part2Problems)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Part 2:", part2Result)
+}
+
+// Print each problem's operands, operator and result in worksheet order
+func explainProblems(out io.Writer, problems []problem) {
+	for i, problem := range problems {
+		operands := make([]string, len(problem.operands))
+		for j, operand := range problem.operands {
+			operands[j] = strconv.Itoa(operand)
+		}
+
+		fmt.Fprintf(out, "Problem %d (columns %d-%d): %s = ",
+			i+1,
+			problem.start,
+			problem.end-1,
+			strings.Join(operands, " "+problem.operation.String()+" "))
 
-	part2Problems := part2ParseInput(string(contents))
-	fmt.Println("Part 2:", part1(part2Problems))
+		if result, err := problem.solve(); err != nil {
+			fmt.Fprintln(out, "error:", err)
+		} else {
+			fmt.Fprintln(out, result)
+		}
+	}
 }
 
-func part1(problems []problem) int {
+func part1(problems []problem) (int, error) {
 	total := 0
 	for _, problem := range problems {
-		total += problem.solve()
+		result, err := problem.solve()
+		if err != nil {
+			return 0, err
+		}
+
+		if total, err = checkedAdd(total, result); err != nil {
+			return 0, err
+		}
 	}
 
-	return total
+	return total, nil
 }
 
-func part1ParseInput(input string) []problem {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
+func part1ParseInput(input string) ([]problem, error) {
+	lines := worksheetLines(input)
 
 	// Problems are layed out in vertical columns
 	// Keep track of the problems using their column index
 	indexedProblems := make(map[int]problem)
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		columns := strings.Split(line, " ")
-
-		idx := 0
-
-		for _, col := range columns {
-			if col == "" {
-				continue
+	for lineIdx, line := range lines {
+		for idx, col := range fieldsWithSpan(line) {
+			problem, set := indexedProblems[idx]
+			if !set {
+				problem.start = col.start
+				problem.end = col.end
 			}
 
-			if _, set := indexedProblems[idx]; !set {
-				indexedProblems[idx] = problem{}
-			}
+			// Widen the problem's span to cover this line's column
+			problem.start = min(problem.start, col.start)
+			problem.end = max(problem.end, col.end)
 
 			// Read either a operand or an operation from the column
-			problem := indexedProblems[idx]
-			switch col {
-			case "+":
-				problem.operation = addition
-			case "*":
-				problem.operation = multiplication
-			default:
-				operand, _ := strconv.Atoi(col)
+			if operand, err := strconv.Atoi(col.text); err == nil {
 				problem.operands = append(problem.operands, operand)
+			} else {
+				op, err := parseOperation(col.text)
+				if err != nil {
+					return nil, fmt.Errorf("line %d, column %d: %w", lineIdx+1, col.start, err)
+				}
+				problem.operation = op
 			}
 			indexedProblems[idx] = problem
+		}
+	}
+
+	return problemsInOrder(indexedProblems), nil
+}
+
+// Return problems sorted by their column index
+func problemsInOrder(indexedProblems map[int]problem) []problem {
+	problems := make([]problem, 0, len(indexedProblems))
+	for _, idx := range slices.Sorted(maps.Keys(indexedProblems)) {
+		problems = append(problems, indexedProblems[idx])
+	}
 
-			idx++
+	return problems
+}
+
+type field struct {
+	text       string
+	start, end int
+}
+
+// Split a line on spaces, keeping track of which columns each field came from
+func fieldsWithSpan(line string) []field {
+	fields := []field{}
+	start := -1
+
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ' ' {
+			if start >= 0 {
+				fields = append(fields, field{line[start:i], start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
 		}
 	}
 
-	values := maps.Values(indexedProblems)
-	return slices.Collect(values)
+	return fields
+}
+
+// Direction the columns of a problem are read in for part 2
+type readDirection int
+
+const (
+	leftToRight readDirection = iota
+	rightToLeft
+)
+
+func parseReadDirection(value string) (readDirection, error) {
+	switch value {
+	case "ltr":
+		return leftToRight, nil
+	case "rtl":
+		return rightToLeft, nil
+	}
+
+	return 0, fmt.Errorf("unknown read direction %q, expected ltr or rtl", value)
 }
 
-func part2ParseInput(input string) []problem {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
+// Tabs are expanded to this many columns so they line up with spaces
+const tabWidth = 8
+
+func part2ParseInput(input string, direction readDirection) ([]problem, error) {
+	lines := worksheetLines(input)
+	if len(lines) < 2 {
+		return nil, errors.New("worksheet needs at least one operand row and an operator row")
+	}
 
 	// Part 2 changes how numbers are read from the input
 	// Instead of a number being read left-to-right, it's read top-to-bottom
+	operandLines := lines[:len(lines)-1]
+	opLine := lines[len(lines)-1]
 
-	// Determine max width of each column
-	columnWidths := columnWidths(lines)
-
-	problems := make(map[int]problem)
+	problems := []problem{}
 
-	for problemIdx, problemWidth := range columnWidths {
-		var problem problem
+	for _, span := range problemSpans(lines) {
+		problem := problem{start: span.start, end: span.end}
 
-		// Determine which column the problem starts on
-		colIdx := 0
-		for i := range problemIdx {
-			colIdx += columnWidths[i] + 1
+		// Operation is on the last line somewhere within the problem's columns
+		symbol := strings.TrimSpace(charsAt(opLine, span.start, span.end))
+		if symbol == "" {
+			return nil, fmt.Errorf("columns %d-%d: %w", span.start, span.end-1, errNoOperator)
 		}
 
-		// Operation is on the last line at the start of the column
-		switch lines[len(lines)-1][colIdx] {
-		case '+':
-			problem.operation = addition
-		case '*':
-			problem.operation = multiplication
+		op, err := parseOperation(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("line %d, columns %d-%d: %w", len(lines), span.start, span.end-1, err)
 		}
+		problem.operation = op
 
 		// Read each digit vertically and build out full number
-		for digitIdx := range problemWidth {
-			var digits string
-			for lineIdx := 0; lineIdx < len(lines)-1; lineIdx++ {
-				digit := string(lines[lineIdx][colIdx+digitIdx])
+		for i := range span.end - span.start {
+			colIdx := span.start + i
+			if direction == rightToLeft {
+				colIdx = span.end - 1 - i
+			}
 
-				if digit != " " {
-					digits += digit
+			var digits string
+			for _, line := range operandLines {
+				if digit := charAt(line, colIdx); digit != ' ' {
+					digits += string(digit)
 				}
 			}
 
 			if len(digits) > 0 {
 				operand, err := strconv.Atoi(digits)
 				if err != nil {
-					panic(err)
+					return nil, fmt.Errorf("column %d: %w", colIdx, err)
 				}
 
 				problem.operands = append(problem.operands, operand)
 			}
 		}
 
-		problems[problemIdx] = problem
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// Split the worksheet into lines, expanding tabs and dropping surrounding blank lines
+// Leading spaces are kept since they are significant to column alignment
+func worksheetLines(input string) []string {
+	lines := []string{}
+
+	for _, line := range strings.Split(input, "\n") {
+		line = expandTabs(strings.TrimRight(line, "\r"))
+
+		if strings.TrimSpace(line) == "" && len(lines) == 0 {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
 	}
 
-	values := maps.Values(problems)
-	return slices.Collect(values)
+	return lines
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var sb strings.Builder
+	col := 0
+	for _, c := range line {
+		if c == '\t' {
+			pad := tabWidth - col%tabWidth
+			sb.WriteString(strings.Repeat(" ", pad))
+			col += pad
+		} else {
+			sb.WriteRune(c)
+			col++
+		}
+	}
+
+	return sb.String()
+}
+
+type span struct {
+	start, end int
 }
 
-func columnWidths(lines []string) map[int]int {
-	widths := make(map[int]int)
+// Find the column spans of each problem
+// Problems are separated by columns that are blank on every line
+// Short lines are treated as blank past their end
+func problemSpans(lines []string) []span {
+	width := 0
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		columns := strings.Split(line, " ")
+		width = max(width, len(line))
+	}
 
-		colIdx := 0
+	spans := []span{}
+	start := -1
 
-		for _, col := range columns {
-			if col == "" {
-				continue
+	for col := 0; col <= width; col++ {
+		blank := true
+		if col < width {
+			for _, line := range lines {
+				if charAt(line, col) != ' ' {
+					blank = false
+					break
+				}
 			}
+		}
 
-			if len(col) > widths[colIdx] {
-				widths[colIdx] = len(col)
+		if blank {
+			if start >= 0 {
+				spans = append(spans, span{start, col})
+				start = -1
 			}
-
-			colIdx++
+		} else if start < 0 {
+			start = col
 		}
 	}
 
-	return widths
+	return spans
+}
+
+func charAt(line string, col int) byte {
+	if col < len(line) {
+		return line[col]
+	}
+
+	return ' '
+}
+
+func charsAt(line string, start, end int) string {
+	if start >= len(line) {
+		return ""
+	}
+
+	return line[start:min(end, len(line))]
 }
@@ -0,0 +1,165 @@
+package day6
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Operation symbol as written on the worksheet
+type operation string
+
+const (
+	addition       operation = "+"
+	subtraction    operation = "-"
+	multiplication operation = "*"
+	division       operation = "/"
+	modulo         operation = "%"
+	power          operation = "^"
+	minimum        operation = "min"
+	maximum        operation = "max"
+)
+
+func (op operation) String() string {
+	return string(op)
+}
+
+// Combines the running result of a problem with its next operand
+type OperatorFunc func(acc, operand int) (int, error)
+
+var (
+	ErrOverflow       = errors.New("integer overflow")
+	ErrDivisionByZero = errors.New("division by zero")
+	errNoOperator     = errors.New("problem has no operator")
+)
+
+var operators = map[operation]OperatorFunc{
+	addition:       checkedAdd,
+	subtraction:    checkedSub,
+	multiplication: checkedMul,
+	division:       checkedDiv,
+	modulo:         checkedMod,
+	power:          checkedPow,
+	minimum: func(acc, operand int) (int, error) {
+		return min(acc, operand), nil
+	},
+	maximum: func(acc, operand int) (int, error) {
+		return max(acc, operand), nil
+	},
+}
+
+// RegisterOperator adds a custom operator that can be used on worksheets
+// Symbols must not be empty, contain spaces, or look like a number
+func RegisterOperator(symbol string, fn OperatorFunc) error {
+	if symbol == "" {
+		return errors.New("operator symbol is empty")
+	}
+
+	for _, c := range symbol {
+		if c == ' ' || c == '\t' {
+			return fmt.Errorf("operator symbol %q contains whitespace", symbol)
+		}
+	}
+
+	if _, err := strconv.Atoi(symbol); err == nil {
+		return fmt.Errorf("operator symbol %q is a number", symbol)
+	}
+
+	if _, exists := operators[operation(symbol)]; exists {
+		return fmt.Errorf("operator %q is already registered", symbol)
+	}
+
+	operators[operation(symbol)] = fn
+	return nil
+}
+
+// Look up an operation by its symbol
+func parseOperation(symbol string) (operation, error) {
+	if _, ok := operators[operation(symbol)]; !ok {
+		return "", fmt.Errorf("unknown operator %q", symbol)
+	}
+
+	return operation(symbol), nil
+}
+
+func checkedAdd(a, b int) (int, error) {
+	if (b > 0 && a > math.MaxInt-b) || (b < 0 && a < math.MinInt-b) {
+		return 0, ErrOverflow
+	}
+
+	return a + b, nil
+}
+
+func checkedSub(a, b int) (int, error) {
+	if (b < 0 && a > math.MaxInt+b) || (b > 0 && a < math.MinInt+b) {
+		return 0, ErrOverflow
+	}
+
+	return a - b, nil
+}
+
+func checkedMul(a, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, ErrOverflow
+	}
+
+	result := a * b
+	if result/b != a {
+		return 0, ErrOverflow
+	}
+
+	return result, nil
+}
+
+func checkedDiv(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	if a == math.MinInt && b == -1 {
+		return 0, ErrOverflow
+	}
+
+	return a / b, nil
+}
+
+func checkedMod(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	if b == -1 {
+		return 0, nil
+	}
+
+	return a % b, nil
+}
+
+func checkedPow(base, exp int) (int, error) {
+	if exp < 0 {
+		return 0, fmt.Errorf("negative exponent %d", exp)
+	}
+
+	// Powers of 0, 1 and -1 never grow, so avoid looping over large exponents
+	if exp > 0 && base >= -1 && base <= 1 {
+		if base == -1 && exp%2 == 0 {
+			return 1, nil
+		}
+		return base, nil
+	}
+
+	result := 1
+	for range exp {
+		var err error
+		if result, err = checkedMul(result, base); err != nil {
+			return 0, err
+		}
+	}
+
+	return result, nil
+}
@@ -1,8 +1,8 @@
 package day7
 
 import (
-	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"strings"
 
@@ -16,6 +16,11 @@ const (
 	start
 	splitter
 	beam
+	mirrorSlash
+	mirrorBackslash
+	splitterVertical
+	splitterHorizontal
+	absorber
 )
 
 type xy struct {
@@ -38,54 +43,15 @@ type grid struct {
 	width, height int
 }
 
-func (g grid) startPoint() (xy, error) {
-	for pt, node := range g.nodes {
-		if node == start {
-			return pt, nil
-		}
-	}
-
-	return xy{}, errors.New("no start point")
-}
-
-// Run simlation to fill the grid with beams as they move from the start and hit splitters
+// Run simlation to fill the grid with beams as they move from the sources through the devices
 func (g grid) simulateBeams() {
-	for y := 0; y < g.height; y++ {
-		for x := 0; x < g.width; x++ {
-			pt := xy{x, y}
-			if g.isStruckByBeam(pt) {
-				switch g.nodes[pt] {
-				case empty:
-					g.placeBeam(pt)
-				case splitter:
-					g.splitBeam(pt)
-				}
-			}
-		}
-	}
-}
-
-func (g grid) isStruckByBeam(pt xy) bool {
-	if node, set := g.nodes[pt.add(up)]; set {
-		return node == beam || node == start
-	}
-
-	return false
-}
-
-func (g grid) placeBeam(pt xy) {
-	if node, set := g.nodes[pt]; set {
-		if node == empty {
+	for pt := range g.traceBeams().energized {
+		if g.nodes[pt] == empty {
 			g.nodes[pt] = beam
 		}
 	}
 }
 
-func (g grid) splitBeam(pt xy) {
-	g.placeBeam(pt.add(left))
-	g.placeBeam(pt.add(right))
-}
-
 func Run(cmd *cobra.Command, args []string) {
 	contents, err := os.ReadFile(args[0])
 	if err != nil {
@@ -99,69 +65,122 @@ func Run(cmd *cobra.Command, args []string) {
 	grid.simulateBeams()
 
 	fmt.Println("Part 1:", part1(grid))
-	fmt.Println("Part 2:", part2(grid))
+
+	part2Result, part2Err := part2(grid)
+	if part2Err != nil {
+		fmt.Println("Part 2:", part2Err)
+	} else {
+		fmt.Println("Part 2:", part2Result)
+		printTimelines(cmd, grid, part2Result)
+	}
+
+	render, _ := cmd.Flags().GetBool("render")
+	svgPath, _ := cmd.Flags().GetString("svg")
+
+	if render || svgPath != "" {
+		t := grid.traceBeams()
+
+		// Timelines can't be counted around a beam loop, but the grid can still be drawn
+		var columns map[int]int
+		if part2Err == nil {
+			columns = columnTimelines(grid, t)
+		}
+
+		if render {
+			renderText(os.Stdout, grid, t, columns)
+		}
+
+		if svgPath != "" {
+			f, err := os.Create(svgPath)
+			if err != nil {
+				panic(err)
+			}
+			defer f.Close()
+
+			renderSvg(f, grid, t, columns)
+		}
+	}
 }
 
-// Part 1 result is how many times the beam hits a splitter
-func part1(grid grid) int {
-	splits := 0
+// Print concrete timelines requested through the command flags
+func printTimelines(cmd *cobra.Command, grid grid, total int) {
+	numFirst, _ := cmd.Flags().GetInt("paths")
+	numSamples, _ := cmd.Flags().GetInt("sample")
+	seed, _ := cmd.Flags().GetUint64("seed")
+	draw, _ := cmd.Flags().GetBool("draw")
 
-	// Look for any splitter getting hit by the beam
-	for y := 0; y < grid.height; y++ {
-		for x := 0; x < grid.width; x++ {
-			pt := xy{x, y}
-			if node, ok := grid.nodes[pt]; ok {
-				if node == splitter && grid.isStruckByBeam(pt) {
-					splits += 1
-				}
+	cache := make(map[beamState]int)
+	timelines := []timeline{}
+
+	if numFirst > 0 {
+		first, err := firstTimelines(grid, numFirst, cache, total)
+		if err != nil {
+			panic(err)
+		}
+		timelines = append(timelines, first...)
+	}
+
+	if numSamples > 0 {
+		rng := rand.New(rand.NewPCG(seed, seed))
+		for range numSamples {
+			t, err := sampleTimeline(grid, rng, cache, total)
+			if err != nil {
+				panic(err)
 			}
+			timelines = append(timelines, t)
 		}
 	}
 
-	return splits
-}
+	if cmd.Flags().Changed("path-index") {
+		k, _ := cmd.Flags().GetInt("path-index")
+		t, err := kthTimeline(grid, k, cache)
+		if err != nil {
+			panic(err)
+		}
+		timelines = append(timelines, t)
+	}
 
-// Part 2 result is how many possible paths the beam can take from the start to the end
-func part2(grid grid) int {
-	// Find start of beam
-	start, _ := grid.startPoint()
+	for _, t := range timelines {
+		if draw {
+			t.draw(os.Stdout, grid)
+			fmt.Println()
+		} else {
+			fmt.Println(t)
+		}
+	}
+}
 
-	// Pass around a cache to avoid computing the same splitter multiple times
-	cache := make(map[xy]int)
+// Part 1 result is how many times the beam hits a splitter
+func part1(grid grid) int {
+	return len(grid.traceBeams().splits)
+}
 
-	return 1 + numPaths(grid, start, cache)
+// Part 2 result is how many possible paths the beam can take from the start to the end
+func part2(grid grid) (int, error) {
+	return grid.countTimelines()
 }
 
 // Determine number of paths a beam can take from a point
-func numPaths(grid grid, pt xy, cache map[xy]int) int {
-	initialPt := pt
-
-	if cnt, ok := cache[initialPt]; ok {
+// Every path ends when the beam leaves the grid or gets absorbed
+// The grid must not contain any cycles
+func numPaths(grid grid, s beamState, cache map[beamState]int) int {
+	if cnt, ok := cache[s]; ok {
 		return cnt
 	}
 
-	// Keep moving the pointer down (the beam only moves down)
-	// until we hit a splitter or the end of the grid
-	for {
-		if node, ok := grid.nodes[pt]; ok {
-			if node == splitter {
-				// A new path is formed if a splitter is hit
-				// The beam is then followed down the left side (considered the same beam) and the right side (the new beam)
-				// Add any other new paths created when following these two beams
-				lPt := pt.add(left)
-				rPt := pt.add(right)
-
-				cnt := 1 + numPaths(grid, lPt, cache) + numPaths(grid, rPt, cache)
-				cache[initialPt] = cnt
-
-				return cnt
-			}
-		} else {
-			return 0
-		}
+	next, _ := grid.step(s)
 
-		pt = pt.add(down)
+	cnt := 0
+	if len(next) == 0 {
+		cnt = 1
 	}
+
+	for _, n := range next {
+		cnt += numPaths(grid, n, cache)
+	}
+
+	cache[s] = cnt
+	return cnt
 }
 
 // Parse into file into initial grid
@@ -184,6 +203,16 @@ func parseInput(input string) grid {
 				nodes[pt] = splitter
 			case 'S':
 				nodes[pt] = start
+			case '/':
+				nodes[pt] = mirrorSlash
+			case '\\':
+				nodes[pt] = mirrorBackslash
+			case '|':
+				nodes[pt] = splitterVertical
+			case '-':
+				nodes[pt] = splitterHorizontal
+			case '#':
+				nodes[pt] = absorber
 			}
 		}
 	}
@@ -0,0 +1,223 @@
+package day7
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A beam occupying a point on the grid while travelling in a direction
+type beamState struct {
+	pt, dir xy
+}
+
+func (s beamState) String() string {
+	return fmt.Sprintf("%d,%d %s", s.pt.x, s.pt.y, dirName(s.dir))
+}
+
+func dirName(dir xy) string {
+	switch dir {
+	case up:
+		return "up"
+	case right:
+		return "right"
+	case down:
+		return "down"
+	case left:
+		return "left"
+	}
+
+	return "?"
+}
+
+func isVertical(dir xy) bool {
+	return dir.x == 0
+}
+
+// Error returned when a beam can loop back onto its own path
+type cycleError struct {
+	states []beamState
+}
+
+func (e cycleError) Error() string {
+	parts := make([]string, len(e.states))
+	for i, s := range e.states {
+		parts[i] = s.String()
+	}
+
+	return "beam loops through " + strings.Join(parts, " -> ")
+}
+
+var errNoSources = errors.New("no start point")
+
+// Result of tracing every beam through the grid
+type trace struct {
+	// Points any beam passed through
+	energized map[xy]bool
+
+	// Splitters that split at least one beam
+	splits map[xy]bool
+
+	// Every point and direction a beam travelled through
+	states map[beamState]bool
+}
+
+// Beams emitted by the sources, sorted top-to-bottom then left-to-right
+func (g grid) sources() []beamState {
+	sources := []beamState{}
+	for pt, node := range g.nodes {
+		if node == start {
+			sources = append(sources, beamState{pt, down})
+		}
+	}
+
+	slices.SortFunc(sources, func(a, b beamState) int {
+		return cmp.Or(cmp.Compare(a.pt.y, b.pt.y), cmp.Compare(a.pt.x, b.pt.x))
+	})
+
+	return sources
+}
+
+// Determine where a beam goes after passing through its current point
+// Beams that leave the grid or get absorbed produce no next states
+func (g grid) step(s beamState) (next []beamState, split bool) {
+	dir := s.dir
+
+	switch g.nodes[s.pt] {
+	case splitter:
+		// Beam is split to either side, continuing in the same direction
+		if isVertical(dir) {
+			next = []beamState{{s.pt.add(left), dir}, {s.pt.add(right), dir}}
+		} else {
+			next = []beamState{{s.pt.add(up), dir}, {s.pt.add(down), dir}}
+		}
+		split = true
+	case splitterVertical:
+		if isVertical(dir) {
+			next = []beamState{{s.pt.add(dir), dir}}
+		} else {
+			next = []beamState{{s.pt.add(up), up}, {s.pt.add(down), down}}
+			split = true
+		}
+	case splitterHorizontal:
+		if isVertical(dir) {
+			next = []beamState{{s.pt.add(left), left}, {s.pt.add(right), right}}
+			split = true
+		} else {
+			next = []beamState{{s.pt.add(dir), dir}}
+		}
+	case mirrorSlash:
+		dir = xy{-dir.y, -dir.x}
+		next = []beamState{{s.pt.add(dir), dir}}
+	case mirrorBackslash:
+		dir = xy{dir.y, dir.x}
+		next = []beamState{{s.pt.add(dir), dir}}
+	case absorber:
+		return nil, false
+	default:
+		next = []beamState{{s.pt.add(dir), dir}}
+	}
+
+	// Drop beams that leave the grid
+	return slices.DeleteFunc(next, func(n beamState) bool {
+		_, ok := g.nodes[n.pt]
+		return !ok
+	}), split
+}
+
+// Follow every beam from the sources until they leave the grid, get absorbed, or repeat
+func (g grid) traceBeams() trace {
+	t := trace{make(map[xy]bool), make(map[xy]bool), make(map[beamState]bool)}
+	queue := g.sources()
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		if t.states[s] {
+			continue
+		}
+		t.states[s] = true
+		t.energized[s.pt] = true
+
+		next, split := g.step(s)
+		if split {
+			t.splits[s.pt] = true
+		}
+
+		queue = append(queue, next...)
+	}
+
+	return t
+}
+
+// Search for a beam that loops back onto its own path
+// Returns the states making up the loop, or nil if every beam eventually ends
+func (g grid) findCycle() []beamState {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	color := make(map[beamState]int)
+	path := []beamState{}
+
+	var visit func(s beamState) []beamState
+	visit = func(s beamState) []beamState {
+		color[s] = visiting
+		path = append(path, s)
+
+		next, _ := g.step(s)
+		for _, n := range next {
+			switch color[n] {
+			case visiting:
+				idx := slices.Index(path, n)
+				return slices.Clone(path[idx:])
+			case unvisited:
+				if cycle := visit(n); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		color[s] = done
+		return nil
+	}
+
+	for _, s := range g.sources() {
+		if color[s] == unvisited {
+			if cycle := visit(s); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// Count the number of timelines, the distinct paths a beam can take from any source
+func (g grid) countTimelines() (int, error) {
+	sources := g.sources()
+	if len(sources) == 0 {
+		return 0, errNoSources
+	}
+
+	// Paths can't be counted if a beam can go around in circles forever
+	if cycle := g.findCycle(); cycle != nil {
+		return 0, cycleError{cycle}
+	}
+
+	// Pass around a cache to avoid computing the same beam multiple times
+	cache := make(map[beamState]int)
+
+	total := 0
+	for _, s := range sources {
+		total += numPaths(g, s, cache)
+	}
+
+	return total, nil
+}
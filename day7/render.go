@@ -0,0 +1,179 @@
+package day7
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const svgCellSize = 12
+
+// Count how many timelines end in each column
+// Forward counts are pushed through the beams in topological order, so the grid must not contain cycles
+func columnTimelines(g grid, t trace) map[int]int {
+	indegree := make(map[beamState]int)
+	for s := range t.states {
+		next, _ := g.step(s)
+		for _, n := range next {
+			indegree[n]++
+		}
+	}
+
+	ways := make(map[beamState]int)
+	queue := []beamState{}
+	for _, s := range g.sources() {
+		ways[s]++
+		if indegree[s] == 0 {
+			queue = append(queue, s)
+		}
+	}
+
+	columns := make(map[int]int)
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		next, _ := g.step(s)
+		if len(next) == 0 {
+			columns[s.pt.x] += ways[s]
+		}
+
+		for _, n := range next {
+			ways[n] += ways[s]
+			indegree[n]--
+			if indegree[n] == 0 {
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return columns
+}
+
+// Direction of the beams passing through each point
+func beamDirections(t trace) (vertical, horizontal map[xy]bool) {
+	vertical = make(map[xy]bool)
+	horizontal = make(map[xy]bool)
+
+	for s := range t.states {
+		if isVertical(s.dir) {
+			vertical[s.pt] = true
+		} else {
+			horizontal[s.pt] = true
+		}
+	}
+
+	return vertical, horizontal
+}
+
+// Print the grid with beams drawn in and hit splitters marked with `*`
+// The footer lists how many timelines end in each column, unless `columns` is nil because the beams loop
+func renderText(out io.Writer, g grid, t trace, columns map[int]int) {
+	vertical, horizontal := beamDirections(t)
+
+	for y := range g.height {
+		var sb strings.Builder
+		for x := range g.width {
+			pt := xy{x, y}
+			node := g.nodes[pt]
+
+			switch {
+			case t.splits[pt]:
+				sb.WriteRune('*')
+			case node == empty || node == beam:
+				switch {
+				case vertical[pt] && horizontal[pt]:
+					sb.WriteRune('+')
+				case vertical[pt]:
+					sb.WriteRune('|')
+				case horizontal[pt]:
+					sb.WriteRune('-')
+				default:
+					sb.WriteRune('.')
+				}
+			default:
+				sb.WriteRune(nodeChar(node))
+			}
+		}
+		fmt.Fprintln(out, sb.String())
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Splitters hit (*): %d\n", len(t.splits))
+	if columns == nil {
+		fmt.Fprintln(out, "Timelines per column: unavailable, the beams loop")
+		return
+	}
+
+	fmt.Fprintln(out, "Timelines per column:")
+	for _, x := range slices.Sorted(maps.Keys(columns)) {
+		fmt.Fprintf(out, "  %d: %d\n", x, columns[x])
+	}
+}
+
+// Draw the grid as an SVG image
+// Beams are drawn as lines, hit splitters are highlighted and timeline counts are written under each column
+// No counts are written if `columns` is nil
+func renderSvg(out io.Writer, g grid, t trace, columns map[int]int) {
+	vertical, horizontal := beamDirections(t)
+	footer := 0
+	for _, cnt := range columns {
+		footer = max(footer, len(fmt.Sprint(cnt)))
+	}
+
+	width := g.width * svgCellSize
+	height := g.height*svgCellSize + (footer+1)*svgCellSize
+	half := svgCellSize / 2
+
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	fmt.Fprintf(out, "<rect width=\"%d\" height=\"%d\" fill=\"#0f0f23\"/>\n", width, height)
+
+	for y := range g.height {
+		for x := range g.width {
+			pt := xy{x, y}
+			cx := x*svgCellSize + half
+			cy := y*svgCellSize + half
+
+			if vertical[pt] {
+				fmt.Fprintf(out, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#ffff66\" stroke-width=\"2\"/>\n", cx, y*svgCellSize, cx, (y+1)*svgCellSize)
+			}
+
+			if horizontal[pt] {
+				fmt.Fprintf(out, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#ffff66\" stroke-width=\"2\"/>\n", x*svgCellSize, cy, (x+1)*svgCellSize, cy)
+			}
+
+			node := g.nodes[pt]
+			if node == empty || node == beam {
+				continue
+			}
+
+			fill := "#cccccc"
+			switch {
+			case t.splits[pt]:
+				fill = "#ff4040"
+			case node == start:
+				fill = "#00cc00"
+			}
+
+			fmt.Fprintf(out, "<text x=\"%d\" y=\"%d\" fill=\"%s\" font-family=\"monospace\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n",
+				cx, cy, fill, svgCellSize, svgEscape(string(nodeChar(node))))
+		}
+	}
+
+	// Timeline counts are written vertically so wide numbers don't overlap
+	for _, x := range slices.Sorted(maps.Keys(columns)) {
+		cx := x*svgCellSize + half
+		cy := g.height*svgCellSize + half
+		fmt.Fprintf(out, "<text x=\"%d\" y=\"%d\" fill=\"#9999cc\" font-family=\"monospace\" font-size=\"%d\" transform=\"rotate(90 %d %d)\" dominant-baseline=\"central\">%d</text>\n",
+			cx, cy, svgCellSize-2, cx, cy, columns[x])
+	}
+
+	fmt.Fprintln(out, "</svg>")
+}
+
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,165 @@
+package day7
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+)
+
+// Branch taken when a beam is split
+type splitChoice struct {
+	hit    beamState
+	branch beamState
+}
+
+// A single concrete path a beam takes from a source until it ends
+type timeline struct {
+	states []beamState
+	splits []splitChoice
+}
+
+// Name the branch taken at a split relative to the beam's direction of travel
+func (c splitChoice) side() string {
+	if isVertical(c.hit.dir) {
+		if c.branch.pt.x < c.hit.pt.x {
+			return "L"
+		}
+		return "R"
+	}
+
+	if c.branch.pt.y < c.hit.pt.y {
+		return "U"
+	}
+	return "D"
+}
+
+// Fetch the k-th timeline (0-based) with the first branch of every split ordered before the second
+// Counts from numPaths are used to skip whole subtrees without walking them
+func kthTimeline(grid grid, k int, cache map[beamState]int) (timeline, error) {
+	if k < 0 {
+		return timeline{}, fmt.Errorf("timeline index %d out of range", k)
+	}
+	idx := k
+
+	for _, s := range grid.sources() {
+		cnt := numPaths(grid, s, cache)
+		if k >= cnt {
+			k -= cnt
+			continue
+		}
+
+		t := timeline{}
+		for {
+			t.states = append(t.states, s)
+
+			next, _ := grid.step(s)
+			if len(next) == 0 {
+				return t, nil
+			}
+
+			chosen := next[0]
+			if len(next) > 1 {
+				for _, n := range next {
+					cnt := numPaths(grid, n, cache)
+					if k < cnt {
+						chosen = n
+						break
+					}
+					k -= cnt
+				}
+
+				t.splits = append(t.splits, splitChoice{s, chosen})
+			}
+
+			s = chosen
+		}
+	}
+
+	return timeline{}, fmt.Errorf("timeline index %d out of range", idx)
+}
+
+// Fetch the first n timelines in order
+func firstTimelines(grid grid, n int, cache map[beamState]int, total int) ([]timeline, error) {
+	timelines := []timeline{}
+	for k := range min(n, total) {
+		t, err := kthTimeline(grid, k, cache)
+		if err != nil {
+			return nil, err
+		}
+		timelines = append(timelines, t)
+	}
+
+	return timelines, nil
+}
+
+// Pick a timeline uniformly at random
+// Each branch is effectively weighted by the number of timelines beneath it
+func sampleTimeline(grid grid, rng *rand.Rand, cache map[beamState]int, total int) (timeline, error) {
+	return kthTimeline(grid, rng.IntN(total), cache)
+}
+
+// Describe a timeline as the sequence of splitters it hits
+func (t timeline) String() string {
+	first := t.states[0]
+	last := t.states[len(t.states)-1]
+
+	parts := []string{fmt.Sprintf("S(%d,%d)", first.pt.x, first.pt.y)}
+
+	for _, c := range t.splits {
+		parts = append(parts, fmt.Sprintf("(%d,%d)%s", c.hit.pt.x, c.hit.pt.y, c.side()))
+	}
+
+	parts = append(parts, fmt.Sprintf("end(%d,%d)", last.pt.x, last.pt.y))
+
+	return strings.Join(parts, " -> ")
+}
+
+// Draw a timeline onto the grid, marking the cells the beam passes through
+func (t timeline) draw(out io.Writer, grid grid) {
+	path := make(map[xy]rune)
+	for _, s := range t.states {
+		if isVertical(s.dir) {
+			path[s.pt] = '|'
+		} else {
+			path[s.pt] = '-'
+		}
+	}
+
+	for y := range grid.height {
+		var sb strings.Builder
+		for x := range grid.width {
+			pt := xy{x, y}
+			node := grid.nodes[pt]
+
+			if r, ok := path[pt]; ok && (node == empty || node == beam) {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteRune(nodeChar(node))
+			}
+		}
+		fmt.Fprintln(out, sb.String())
+	}
+}
+
+// Character used to represent a node in the input
+func nodeChar(n node) rune {
+	switch n {
+	case start:
+		return 'S'
+	case splitter:
+		return '^'
+	case mirrorSlash:
+		return '/'
+	case mirrorBackslash:
+		return '\\'
+	case splitterVertical:
+		return '|'
+	case splitterHorizontal:
+		return '-'
+	case absorber:
+		return '#'
+	}
+
+	return '.'
+}
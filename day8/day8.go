@@ -1,11 +1,12 @@
 package day8
 
 import (
+	"cmp"
 	"fmt"
-	"math"
+	"io"
+	"maps"
 	"os"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -21,13 +22,15 @@ type xyz struct {
 // Fuse box
 type box struct {
 	id        uuid.UUID
+	index     int
 	pos       xyz
 	circuitId uuid.UUID
 }
 
-// Euclidian distance between two boxes
+// Distance between two boxes
 type measurement struct {
 	box1Id, box2Id uuid.UUID
+	rank           int
 	distance       float64
 }
 
@@ -36,20 +39,84 @@ func Run(cmd *cobra.Command, args []string) {
 	if err != nil {
 		panic(err)
 	}
-	iterations, err := strconv.Atoi(args[1])
+
+	iterations, _ := cmd.Flags().GetInt("iterations")
+	if iterations < 0 {
+		panic(fmt.Errorf("invalid iterations %d, expected at least 0", iterations))
+	}
+
+	top, _ := cmd.Flags().GetInt("top")
+	if top < 1 {
+		panic(fmt.Errorf("invalid top %d, expected at least 1", top))
+	}
+	aggregate, _ := cmd.Flags().GetString("aggregate")
+
+	metricName, _ := cmd.Flags().GetString("metric")
+	metric, err := parseMetric(metricName)
 	if err != nil {
 		panic(err)
 	}
 
 	boxes := parseInput(string(contents))
-	measurements := measureBoxes(boxes)
+	measurements := measureBoxes(boxes, metric)
+
+	if format, _ := cmd.Flags().GetString("mst"); format != "" {
+		if err := exportTree(os.Stdout, format, spanningTree(boxes, measurements)); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if cmd.Flags().Changed("until") {
+		size, _ := cmd.Flags().GetInt("until")
+		if cnt, ok := connectionsUntil(boxes, measurements, size); ok {
+			fmt.Printf("Largest circuit exceeds %d boxes after %d connections\n", size, cnt)
+		} else {
+			fmt.Printf("Largest circuit never exceeds %d boxes\n", size)
+		}
+	}
+
+	if aggregate == "histogram" {
+		fmt.Println("Part 1:")
+		printHistogram(os.Stdout, connectClosest(boxes, measurements, iterations))
+	} else {
+		part1Result, err := part1(boxes, measurements, iterations, top, aggregate)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("Part 1:", part1Result)
+	}
 
-	fmt.Println("Part 1:", part1(boxes, measurements, iterations))
 	fmt.Println("Part 2:", part2(boxes, measurements))
 }
 
-// Part 1 result is the product of the top 3 circuits after connecting the closest boxes
-func part1(boxes map[uuid.UUID]box, measurements []measurement, iterations int) int {
+// Part 1 result combines the largest circuits after connecting the closest boxes
+// The puzzle multiplies the top 3 circuits together
+func part1(boxes map[uuid.UUID]box, measurements []measurement, iterations int, top int, aggregate string) (int, error) {
+	circuitSizes := connectClosest(boxes, measurements, iterations)
+	circuitSizes = circuitSizes[:min(top, len(circuitSizes))]
+
+	switch aggregate {
+	case "product":
+		result := 1
+		for _, size := range circuitSizes {
+			result *= size
+		}
+		return result, nil
+	case "sum":
+		result := 0
+		for _, size := range circuitSizes {
+			result += size
+		}
+		return result, nil
+	}
+
+	return 0, fmt.Errorf("unknown aggregate %q, expected product, sum or histogram", aggregate)
+}
+
+// Connect the closest boxes for the given number of iterations
+// Returns the size of every circuit, largest first
+func connectClosest(boxes map[uuid.UUID]box, measurements []measurement, iterations int) []int {
 	circuits := make(map[uuid.UUID]int)
 	cnt := 0
 
@@ -73,20 +140,7 @@ func part1(boxes map[uuid.UUID]box, measurements []measurement, iterations int)
 			continue
 		}
 
-		// Merge circuits into new circuit with new ID
-		mergedCircuitId := uuid.New()
-
-		// Update all boxes in the merged circuit with the new ID
-		for boxId, box := range boxes {
-			switch box.circuitId {
-			case box1.circuitId:
-				box.circuitId = mergedCircuitId
-			case box2.circuitId:
-				box.circuitId = mergedCircuitId
-			}
-
-			boxes[boxId] = box
-		}
+		mergedCircuitId := mergeCircuits(boxes, box1.circuitId, box2.circuitId)
 
 		// Update circuit box counts
 		circuits[mergedCircuitId] = circuits[box1.circuitId] + circuits[box2.circuitId]
@@ -103,12 +157,83 @@ func part1(boxes map[uuid.UUID]box, measurements []measurement, iterations int)
 	slices.Sort(circuitSizes)
 	slices.Reverse(circuitSizes)
 
-	result := circuitSizes[0]
-	for i := 1; i < 3; i++ {
-		result *= circuitSizes[i]
+	return circuitSizes
+}
+
+// Print how many circuits there are of each size, largest first
+func printHistogram(out io.Writer, circuitSizes []int) {
+	counts := make(map[int]int)
+	for _, size := range circuitSizes {
+		counts[size]++
 	}
 
-	return result
+	sizes := slices.Sorted(maps.Keys(counts))
+	slices.Reverse(sizes)
+
+	for _, size := range sizes {
+		fmt.Fprintf(out, "%d: %d\n", size, counts[size])
+	}
+}
+
+// Find how many connections are made before the largest circuit first has more than `size` boxes
+// Runs on a fresh copy of the boxes so the caller's circuits are left alone
+func connectionsUntil(boxes map[uuid.UUID]box, measurements []measurement, size int) (int, bool) {
+	boxes = resetCircuits(boxes)
+	circuits := make(map[uuid.UUID]int)
+
+	for _, box := range boxes {
+		circuits[box.circuitId] = 1
+	}
+
+	if size < 1 && len(boxes) > 0 {
+		return 0, true
+	}
+
+	for i, measurement := range measurements {
+		box1 := boxes[measurement.box1Id]
+		box2 := boxes[measurement.box2Id]
+
+		if box1.circuitId == box2.circuitId {
+			continue
+		}
+
+		mergedCircuitId := mergeCircuits(boxes, box1.circuitId, box2.circuitId)
+		circuits[mergedCircuitId] = circuits[box1.circuitId] + circuits[box2.circuitId]
+		delete(circuits, box1.circuitId)
+		delete(circuits, box2.circuitId)
+
+		if circuits[mergedCircuitId] > size {
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// Merge two circuits into a new circuit with a new ID
+// All boxes in either circuit are updated with the new ID
+func mergeCircuits(boxes map[uuid.UUID]box, circuit1, circuit2 uuid.UUID) uuid.UUID {
+	mergedCircuitId := uuid.New()
+
+	for boxId, box := range boxes {
+		if box.circuitId == circuit1 || box.circuitId == circuit2 {
+			box.circuitId = mergedCircuitId
+			boxes[boxId] = box
+		}
+	}
+
+	return mergedCircuitId
+}
+
+// Copy the boxes with every box in its own circuit
+func resetCircuits(boxes map[uuid.UUID]box) map[uuid.UUID]box {
+	boxes = maps.Clone(boxes)
+	for boxId, box := range boxes {
+		box.circuitId = uuid.New()
+		boxes[boxId] = box
+	}
+
+	return boxes
 }
 
 // Part 2 connects all the remaining boxes together
@@ -134,20 +259,7 @@ func part2(boxes map[uuid.UUID]box, measurements []measurement) int {
 				continue
 			}
 
-			// Merge circuits into new circuit with new ID
-			mergedCircuitId := uuid.New()
-
-			// Update all boxes in the merged circuit with the new ID
-			for boxId, box := range boxes {
-				switch box.circuitId {
-				case box1.circuitId:
-					box.circuitId = mergedCircuitId
-				case box2.circuitId:
-					box.circuitId = mergedCircuitId
-				}
-
-				boxes[boxId] = box
-			}
+			mergedCircuitId := mergeCircuits(boxes, box1.circuitId, box2.circuitId)
 
 			circuits[mergedCircuitId] = true
 			delete(circuits, box1.circuitId)
@@ -160,48 +272,29 @@ func part2(boxes map[uuid.UUID]box, measurements []measurement) int {
 	return lastMergeProduct
 }
 
-// Get straight-line distance between two points
-func distance(pt1 xyz, pt2 xyz) float64 {
-	return math.Sqrt(math.Pow(float64(pt2.x-pt1.x), 2) + math.Pow(float64(pt2.y-pt1.y), 2) + math.Pow(float64(pt2.z-pt1.z), 2))
-}
-
 // Measure distances between all boxes
-func measureBoxes(boxes map[uuid.UUID]box) []measurement {
-	measurementsMap := make(map[string]measurement)
-
-	for _, box1 := range boxes {
-		for _, box2 := range boxes {
-			if box1 == box2 {
-				continue
-			}
-
-			var mapKey string
-			if box1.id.String() < box2.id.String() {
-				mapKey = box1.id.String() + "-" + box2.id.String()
-			} else {
-				mapKey = box2.id.String() + "-" + box1.id.String()
-			}
-
-			// Have we already measured these boxes?
-			if _, set := measurementsMap[mapKey]; set {
-				continue
-			}
-
-			distance := distance(box1.pos, box2.pos)
-			measurement := measurement{box1.id, box2.id, distance}
-
-			measurementsMap[mapKey] = measurement
-		}
+// Pairs are sorted by distance, with ties broken by the boxes' order in the input
+func measureBoxes(boxes map[uuid.UUID]box, metric metric) []measurement {
+	sorted := make([]box, 0, len(boxes))
+	for _, box := range boxes {
+		sorted = append(sorted, box)
 	}
+	sortBoxes(sorted)
 
-	measurements := make([]measurement, 0, len(measurementsMap))
-	for _, measurement := range measurementsMap {
-		measurements = append(measurements, measurement)
+	measurements := make([]measurement, 0, len(sorted)*(len(sorted)-1)/2)
+	for i, box1 := range sorted {
+		for _, box2 := range sorted[i+1:] {
+			rank := metric.rank(box1.pos, box2.pos)
+			measurements = append(measurements, measurement{box1.id, box2.id, rank, metric.distance(rank)})
+		}
 	}
 
-	// Sort by distance
-	sort.Slice(measurements, func(i, j int) bool {
-		return measurements[i].distance < measurements[j].distance
+	slices.SortFunc(measurements, func(m1, m2 measurement) int {
+		return cmp.Or(
+			cmp.Compare(m1.rank, m2.rank),
+			cmp.Compare(boxes[m1.box1Id].index, boxes[m2.box1Id].index),
+			cmp.Compare(boxes[m1.box2Id].index, boxes[m2.box2Id].index),
+		)
 	})
 
 	return measurements
@@ -213,13 +306,14 @@ func parseInput(input string) map[uuid.UUID]box {
 	//boxes := []box{}
 	boxes := make(map[uuid.UUID]box)
 
-	for _, line := range lines {
+	for i, line := range lines {
 		parsed := strings.Split(strings.TrimSpace(line), ",")
 		x, _ := strconv.Atoi(parsed[0])
 		y, _ := strconv.Atoi(parsed[1])
 		z, _ := strconv.Atoi(parsed[2])
 		box := box{
 			id:        uuid.New(),
+			index:     i,
 			pos:       xyz{x, y, z},
 			circuitId: uuid.New(),
 		}
@@ -0,0 +1,60 @@
+package day8
+
+import (
+	"fmt"
+	"math"
+)
+
+// Method of measuring the distance between two boxes
+// `rank` is an exact integer that orders pairs the same way as the reported distance
+type metric struct {
+	rank     func(pt1, pt2 xyz) int
+	distance func(rank int) float64
+}
+
+var metrics = map[string]metric{
+	"euclidean": {squaredEuclidean, func(rank int) float64 { return math.Sqrt(float64(rank)) }},
+	"squared":   {squaredEuclidean, identityDistance},
+	"manhattan": {manhattan, identityDistance},
+	"chebyshev": {chebyshev, identityDistance},
+}
+
+func parseMetric(name string) (metric, error) {
+	m, ok := metrics[name]
+	if !ok {
+		return metric{}, fmt.Errorf("unknown metric %q, expected euclidean, squared, manhattan or chebyshev", name)
+	}
+
+	return m, nil
+}
+
+func identityDistance(rank int) float64 {
+	return float64(rank)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+// Squared straight-line distance, which sorts the same as the straight-line distance without needing floats
+func squaredEuclidean(pt1 xyz, pt2 xyz) int {
+	dx := pt2.x - pt1.x
+	dy := pt2.y - pt1.y
+	dz := pt2.z - pt1.z
+
+	return dx*dx + dy*dy + dz*dz
+}
+
+// Distance travelled along conduits running parallel to the axes
+func manhattan(pt1 xyz, pt2 xyz) int {
+	return abs(pt2.x-pt1.x) + abs(pt2.y-pt1.y) + abs(pt2.z-pt1.z)
+}
+
+// Largest distance along any single axis
+func chebyshev(pt1 xyz, pt2 xyz) int {
+	return max(abs(pt2.x-pt1.x), abs(pt2.y-pt1.y), abs(pt2.z-pt1.z))
+}
@@ -0,0 +1,174 @@
+package day8
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Cable connecting two boxes in the wiring plan
+type connection struct {
+	box1, box2 box
+	distance   float64
+}
+
+// Minimum spanning tree of the boxes
+type wiringPlan struct {
+	boxes       []box
+	connections []connection
+	total       float64
+}
+
+// Connect the closest boxes until every box is in a single circuit (Kruskal's algorithm)
+// Connections are returned in the order they were merged
+func spanningTree(boxes map[uuid.UUID]box, measurements []measurement) wiringPlan {
+	// Work on a copy with every box in its own circuit so the caller's circuits are left alone
+	boxes = resetCircuits(boxes)
+
+	plan := wiringPlan{}
+	for _, box := range boxes {
+		plan.boxes = append(plan.boxes, box)
+	}
+	sortBoxes(plan.boxes)
+
+	for _, measurement := range measurements {
+		if len(plan.connections) == len(boxes)-1 {
+			break
+		}
+
+		box1 := boxes[measurement.box1Id]
+		box2 := boxes[measurement.box2Id]
+
+		// Skip if already attached to the same circuit
+		if box1.circuitId == box2.circuitId {
+			continue
+		}
+
+		mergeCircuits(boxes, box1.circuitId, box2.circuitId)
+
+		plan.connections = append(plan.connections, connection{box1, box2, measurement.distance})
+		plan.total += measurement.distance
+	}
+
+	return plan
+}
+
+// Sort boxes into the order they were listed in the input
+func sortBoxes(boxes []box) {
+	slices.SortFunc(boxes, func(a, b box) int {
+		return a.index - b.index
+	})
+}
+
+// Write the wiring plan in the given format (text, csv, dot or json)
+func exportTree(out io.Writer, format string, plan wiringPlan) error {
+	switch format {
+	case "text":
+		return exportTreeText(out, plan)
+	case "csv":
+		return exportTreeCsv(out, plan)
+	case "dot":
+		return exportTreeDot(out, plan)
+	case "json":
+		return exportTreeJson(out, plan)
+	}
+
+	return fmt.Errorf("unknown mst format %q, expected text, csv, dot or json", format)
+}
+
+func boxLabel(b box) string {
+	return fmt.Sprintf("%d,%d,%d", b.pos.x, b.pos.y, b.pos.z)
+}
+
+func exportTreeText(out io.Writer, plan wiringPlan) error {
+	for i, c := range plan.connections {
+		fmt.Fprintf(out, "%d: %s -> %s (%.3f)\n", i+1, boxLabel(c.box1), boxLabel(c.box2), c.distance)
+	}
+
+	_, err := fmt.Fprintf(out, "Total cable length: %.3f\n", plan.total)
+	return err
+}
+
+func exportTreeCsv(out io.Writer, plan wiringPlan) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"order", "box1", "x1", "y1", "z1", "box2", "x2", "y2", "z2", "distance"})
+
+	for i, c := range plan.connections {
+		w.Write([]string{
+			strconv.Itoa(i + 1),
+			strconv.Itoa(c.box1.index),
+			strconv.Itoa(c.box1.pos.x),
+			strconv.Itoa(c.box1.pos.y),
+			strconv.Itoa(c.box1.pos.z),
+			strconv.Itoa(c.box2.index),
+			strconv.Itoa(c.box2.pos.x),
+			strconv.Itoa(c.box2.pos.y),
+			strconv.Itoa(c.box2.pos.z),
+			strconv.FormatFloat(c.distance, 'f', -1, 64),
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func exportTreeDot(out io.Writer, plan wiringPlan) error {
+	fmt.Fprintln(out, "graph wiring {")
+	fmt.Fprintf(out, "  label=\"Total cable length: %.3f\";\n", plan.total)
+
+	for _, b := range plan.boxes {
+		fmt.Fprintf(out, "  b%d [label=\"%s\"];\n", b.index, boxLabel(b))
+	}
+
+	for i, c := range plan.connections {
+		fmt.Fprintf(out, "  b%d -- b%d [label=\"#%d %.3f\"];\n", c.box1.index, c.box2.index, i+1, c.distance)
+	}
+
+	_, err := fmt.Fprintln(out, "}")
+	return err
+}
+
+type jsonBox struct {
+	Index int `json:"index"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Z     int `json:"z"`
+}
+
+type jsonConnection struct {
+	Order    int     `json:"order"`
+	Box1     int     `json:"box1"`
+	Box2     int     `json:"box2"`
+	Distance float64 `json:"distance"`
+}
+
+type jsonPlan struct {
+	Boxes       []jsonBox        `json:"boxes"`
+	Connections []jsonConnection `json:"connections"`
+	Total       float64          `json:"total"`
+}
+
+func exportTreeJson(out io.Writer, plan wiringPlan) error {
+	result := jsonPlan{
+		Boxes:       []jsonBox{},
+		Connections: []jsonConnection{},
+		Total:       plan.total,
+	}
+
+	for _, b := range plan.boxes {
+		result.Boxes = append(result.Boxes, jsonBox{b.index, b.pos.x, b.pos.y, b.pos.z})
+	}
+
+	for i, c := range plan.connections {
+		result.Connections = append(result.Connections, jsonConnection{i + 1, c.box1.index, c.box2.index, c.distance})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
@@ -0,0 +1,275 @@
+// Package geometry works with rectilinear polygons on integer coordinates.
+//
+// Coordinates follow the puzzle's layout, with y increasing downward.
+// Polygons are closed: the last vertex connects back to the first.
+// Points on a polygon's boundary are considered inside it.
+package geometry
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Point struct {
+	X, Y int
+}
+
+// Rectangle spanning two opposite corners, inclusive
+type Rect struct {
+	Min, Max Point
+}
+
+// RectFromCorners builds a rectangle from any two opposite corners
+func RectFromCorners(p1, p2 Point) Rect {
+	return Rect{
+		Point{min(p1.X, p2.X), min(p1.Y, p2.Y)},
+		Point{max(p1.X, p2.X), max(p1.Y, p2.Y)},
+	}
+}
+
+// Edge between two consecutive vertices
+type Edge struct {
+	From, To Point
+}
+
+func (e Edge) vertical() bool {
+	return e.From.X == e.To.X
+}
+
+type Orientation int
+
+const (
+	Degenerate Orientation = iota
+	Clockwise
+	CounterClockwise
+)
+
+func (o Orientation) String() string {
+	switch o {
+	case Clockwise:
+		return "clockwise"
+	case CounterClockwise:
+		return "counter-clockwise"
+	}
+
+	return "degenerate"
+}
+
+// Polygon whose edges are all horizontal or vertical
+type Polygon struct {
+	vertices []Point
+
+	// Outline of the tiles inside the polygon, in doubled coordinates
+	tileOutline []Edge
+}
+
+var ErrTooFewVertices = errors.New("polygon needs at least 4 vertices")
+
+// NewPolygon builds a polygon from its vertices in order
+// Every edge, including the one closing the loop, must be horizontal or vertical
+func NewPolygon(vertices []Point) (Polygon, error) {
+	if len(vertices) < 4 {
+		return Polygon{}, ErrTooFewVertices
+	}
+
+	p := Polygon{vertices: slices.Clone(vertices)}
+	for _, e := range p.Edges() {
+		if e.From.X != e.To.X && e.From.Y != e.To.Y {
+			return Polygon{}, fmt.Errorf("cannot connect %d,%d to %d,%d with a horizontal or vertical edge", e.From.X, e.From.Y, e.To.X, e.To.Y)
+		}
+	}
+
+	p.tileOutline = p.outlineTiles()
+
+	return p, nil
+}
+
+func (p Polygon) Vertices() []Point {
+	return p.vertices
+}
+
+func (p Polygon) Edges() []Edge {
+	edges := make([]Edge, len(p.vertices))
+	for i, v := range p.vertices {
+		edges[i] = Edge{v, p.vertices[(i+1)%len(p.vertices)]}
+	}
+
+	return edges
+}
+
+// Twice the signed area given by the shoelace formula
+// Positive when the vertices run clockwise on a y-down grid
+func (p Polygon) signedArea2() int {
+	sum := 0
+	for _, e := range p.Edges() {
+		sum += e.From.X*e.To.Y - e.To.X*e.From.Y
+	}
+
+	return sum
+}
+
+// Area enclosed by the polygon's edges
+// The area of a rectilinear polygon with integer vertices is always a whole number
+func (p Polygon) Area() int {
+	a := p.signedArea2()
+	if a < 0 {
+		a = -a
+	}
+
+	return a / 2
+}
+
+// Direction the vertices wind around the polygon on a y-down grid
+func (p Polygon) Orientation() Orientation {
+	switch a := p.signedArea2(); {
+	case a > 0:
+		return Clockwise
+	case a < 0:
+		return CounterClockwise
+	}
+
+	return Degenerate
+}
+
+// Contains reports whether a point is inside the polygon or on its boundary
+func (p Polygon) Contains(pt Point) bool {
+	return p.containsScaled(Point{pt.X * 2, pt.Y * 2}, 2)
+}
+
+// Check a point against the polygon with all of the polygon's coordinates multiplied by `scale`
+// This allows testing points halfway between integer coordinates
+func (p Polygon) containsScaled(pt Point, scale int) bool {
+	inside := false
+
+	for _, e := range p.Edges() {
+		from := Point{e.From.X * scale, e.From.Y * scale}
+		to := Point{e.To.X * scale, e.To.Y * scale}
+
+		// Points on the boundary are inside
+		if pt.X >= min(from.X, to.X) && pt.X <= max(from.X, to.X) &&
+			pt.Y >= min(from.Y, to.Y) && pt.Y <= max(from.Y, to.Y) {
+			return true
+		}
+
+		// Cast a ray to the right, counting the vertical edges it crosses
+		// Half-open spans stop a ray through a vertex from counting twice
+		if from.X == to.X && from.X > pt.X && (from.Y > pt.Y) != (to.Y > pt.Y) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// ContainsRect reports whether every tile of the rectangle is inside the polygon or on its boundary
+// Tiles are the integer points, so a strip of outside between two edges one apart doesn't stop a
+// rectangle spanning it, as there are no tiles in the strip.
+func (p Polygon) ContainsRect(r Rect) bool {
+	// Grow the rectangle by half a tile on each side so it covers the same ground as its tiles
+	grown := Rect{
+		Point{r.Min.X*2 - 1, r.Min.Y*2 - 1},
+		Point{r.Max.X*2 + 1, r.Max.Y*2 + 1},
+	}
+
+	// If no edge of the outline passes through the grown rectangle, it's entirely inside or entirely outside
+	for _, e := range p.tileOutline {
+		if crossesInterior(e, grown) {
+			return false
+		}
+	}
+
+	// Cast a ray to the right of the center, counting the vertical edges it crosses
+	center := Point{r.Min.X + r.Max.X, r.Min.Y + r.Max.Y}
+	inside := false
+	for _, e := range p.tileOutline {
+		if e.vertical() && e.From.X > center.X && (e.From.Y > center.Y) != (e.To.Y > center.Y) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Check if an edge passes through the open interior of a rectangle
+func crossesInterior(e Edge, r Rect) bool {
+	if e.vertical() {
+		lo, hi := min(e.From.Y, e.To.Y), max(e.From.Y, e.To.Y)
+		return e.From.X > r.Min.X && e.From.X < r.Max.X && max(lo, r.Min.Y) < min(hi, r.Max.Y)
+	}
+
+	lo, hi := min(e.From.X, e.To.X), max(e.From.X, e.To.X)
+	return e.From.Y > r.Min.Y && e.From.Y < r.Max.Y && max(lo, r.Min.X) < min(hi, r.Max.X)
+}
+
+// Trace the outline of the polygon's tiles, covering every point within half a tile of the polygon
+// Each edge is pushed half a tile outward, in doubled coordinates so everything stays an integer.
+// Where two edges are one apart with the outside between them, the pushed edges land on top of
+// each other and cancel out, since there are no tiles between them to keep out.
+func (p Polygon) outlineTiles() []Edge {
+	sign := 1
+	if p.Orientation() == CounterClockwise {
+		sign = -1
+	}
+
+	edges := []Edge{}
+	for _, e := range p.Edges() {
+		if e.From != e.To {
+			edges = append(edges, e)
+		}
+	}
+
+	// Direction pointing out of the polygon from an edge
+	outward := func(e Edge) Point {
+		dx, dy := cmp.Compare(e.To.X, e.From.X), cmp.Compare(e.To.Y, e.From.Y)
+		return Point{dy * sign, -dx * sign}
+	}
+
+	// Push each vertex out from both of the edges meeting there
+	pushed := make([]Point, len(edges))
+	for i, e := range edges {
+		prev := outward(edges[(i+len(edges)-1)%len(edges)])
+		cur := outward(e)
+		if prev == cur {
+			prev = Point{}
+		}
+
+		pushed[i] = Point{e.From.X*2 + prev.X + cur.X, e.From.Y*2 + prev.Y + cur.Y}
+	}
+
+	// Collect the ends of the pushed edges along each line
+	type line struct {
+		vertical bool
+		at       int
+	}
+
+	ends := map[line][]int{}
+	for i := range pushed {
+		from, to := pushed[i], pushed[(i+1)%len(pushed)]
+		if from.X == to.X {
+			ends[line{true, from.X}] = append(ends[line{true, from.X}], from.Y, to.Y)
+		} else {
+			ends[line{false, from.Y}] = append(ends[line{false, from.Y}], from.X, to.X)
+		}
+	}
+
+	// Only stretches covered by an odd number of pushed edges are left in the outline
+	outline := []Edge{}
+	for l, at := range ends {
+		slices.Sort(at)
+		for i := 0; i+1 < len(at); i += 2 {
+			if at[i] == at[i+1] {
+				continue
+			}
+
+			if l.vertical {
+				outline = append(outline, Edge{Point{l.at, at[i]}, Point{l.at, at[i+1]}})
+			} else {
+				outline = append(outline, Edge{Point{at[i], l.at}, Point{at[i+1], l.at}})
+			}
+		}
+	}
+
+	return outline
+}
@@ -0,0 +1,105 @@
+package geometry
+
+import (
+	"slices"
+	"testing"
+)
+
+func mustPolygon(t *testing.T, vertices []Point) Polygon {
+	t.Helper()
+
+	p, err := NewPolygon(vertices)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+var (
+	// L shape with the notch cut from the bottom right
+	lShape = []Point{{2, 1}, {7, 1}, {7, 4}, {4, 4}, {4, 6}, {2, 6}}
+
+	// Edges at x=5 and x=6 leave a one-wide slot open to the bottom, with nothing in it but area
+	oneWideNotch = []Point{{0, 0}, {5, 0}, {5, 4}, {6, 4}, {6, 0}, {10, 0}, {10, 6}, {0, 6}}
+
+	// Outside pocket at x=6, y=4..5 that only opens to the outside through a one-wide mouth
+	pocket = []Point{
+		{0, 3}, {3, 3}, {3, 0}, {6, 0}, {6, 3}, {5, 3}, {5, 6}, {7, 6},
+		{7, 3}, {10, 3}, {10, 8}, {7, 8}, {7, 10}, {3, 10}, {3, 6}, {0, 6},
+	}
+)
+
+func TestContainsRect(t *testing.T) {
+	tests := []struct {
+		name     string
+		vertices []Point
+		rect     Rect
+		want     bool
+	}{
+		{"l shape whole top", lShape, Rect{Point{2, 1}, Point{7, 4}}, true},
+		{"l shape whole left", lShape, Rect{Point{2, 1}, Point{4, 6}}, true},
+		{"l shape over notch", lShape, Rect{Point{2, 1}, Point{7, 6}}, false},
+		{"l shape beyond bounds", lShape, Rect{Point{1, 1}, Point{4, 6}}, false},
+
+		{"notch edges one apart", oneWideNotch, Rect{Point{0, 0}, Point{10, 6}}, true},
+		{"notch single edge", oneWideNotch, Rect{Point{5, 0}, Point{6, 6}}, true},
+
+		{"pocket covered", pocket, Rect{Point{3, 3}, Point{7, 8}}, false},
+		{"pocket under largest", pocket, Rect{Point{5, 3}, Point{10, 8}}, false},
+		{"pocket mouth", pocket, Rect{Point{5, 3}, Point{7, 6}}, false},
+		{"pocket beside", pocket, Rect{Point{3, 6}, Point{7, 10}}, true},
+		{"pocket wall", pocket, Rect{Point{3, 0}, Point{5, 10}}, true},
+
+		{"segment along edge", pocket, Rect{Point{0, 3}, Point{3, 3}}, true},
+		{"segment across mouth", pocket, Rect{Point{5, 5}, Point{7, 5}}, false},
+		{"segment inside", pocket, Rect{Point{4, 1}, Point{4, 9}}, true},
+		{"single point outside", pocket, Rect{Point{6, 4}, Point{6, 4}}, false},
+		{"single point vertex", pocket, Rect{Point{10, 8}, Point{10, 8}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mustPolygon(t, tt.vertices)
+			if got := p.ContainsRect(tt.rect); got != tt.want {
+				t.Errorf("ContainsRect(%v) = %v, want %v", tt.rect, got, tt.want)
+			}
+		})
+	}
+}
+
+// A rectangle should be inside exactly when every point in it is
+func TestContainsRectMatchesPoints(t *testing.T) {
+	for _, vertices := range [][]Point{lShape, oneWideNotch, pocket} {
+		p := mustPolygon(t, vertices)
+
+		// Check with the vertices in both directions
+		reversed := slices.Clone(vertices)
+		slices.Reverse(reversed)
+		q := mustPolygon(t, reversed)
+
+		for y1 := -1; y1 <= 11; y1++ {
+			for x1 := -1; x1 <= 11; x1++ {
+				for y2 := y1; y2 <= 11; y2++ {
+					for x2 := x1; x2 <= 11; x2++ {
+						r := Rect{Point{x1, y1}, Point{x2, y2}}
+
+						want := true
+						for y := y1; y <= y2 && want; y++ {
+							for x := x1; x <= x2 && want; x++ {
+								want = p.Contains(Point{x, y})
+							}
+						}
+
+						if got := p.ContainsRect(r); got != want {
+							t.Errorf("%v: ContainsRect(%v) = %v, want %v", vertices, r, got, want)
+						}
+						if got := q.ContainsRect(r); got != want {
+							t.Errorf("%v reversed: ContainsRect(%v) = %v, want %v", vertices, r, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package geometry
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+type IssueKind int
+
+const (
+	TooFewVertices IssueKind = iota
+	DiagonalEdge
+	ZeroLengthEdge
+	RepeatedVertex
+	CollinearVertex
+	CrossingEdges
+	OverlappingEdges
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case TooFewVertices:
+		return "too few vertices"
+	case DiagonalEdge:
+		return "diagonal edge"
+	case ZeroLengthEdge:
+		return "zero-length edge"
+	case RepeatedVertex:
+		return "repeated vertex"
+	case CollinearVertex:
+		return "collinear redundant vertex"
+	case CrossingEdges:
+		return "crossing edges"
+	case OverlappingEdges:
+		return "overlapping edges"
+	}
+
+	return "unknown issue"
+}
+
+// Redundant vertices don't stop the loop from being a valid polygon
+func (k IssueKind) Fatal() bool {
+	return k != CollinearVertex
+}
+
+// Problem found with a loop of vertices
+// Vertices holds the indexes of the vertices involved
+// For crossing and overlapping edges, each edge is identified by the index of its starting vertex
+type Issue struct {
+	Kind     IssueKind
+	Vertices []int
+	At       Point
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s at %d,%d", i.Kind, i.At.X, i.At.Y)
+}
+
+// Validate checks that a loop of vertices forms a simple rectilinear polygon
+// Every problem is reported, sorted by the first vertex involved
+func Validate(vertices []Point) []Issue {
+	issues := []Issue{}
+	n := len(vertices)
+	if n < 4 {
+		issues = append(issues, Issue{Kind: TooFewVertices, Vertices: []int{}})
+		if n == 0 {
+			return issues
+		}
+	}
+
+	edges := make([]Edge, n)
+	for i := range vertices {
+		edges[i] = Edge{vertices[i], vertices[(i+1)%n]}
+	}
+
+	for i, e := range edges {
+		next := (i + 1) % n
+
+		switch {
+		case e.From == e.To:
+			issues = append(issues, Issue{ZeroLengthEdge, []int{i, next}, e.From})
+		case e.From.X != e.To.X && e.From.Y != e.To.Y:
+			issues = append(issues, Issue{DiagonalEdge, []int{i, next}, e.From})
+		}
+	}
+
+	// Vertices visited more than once, other than back-to-back duplicates which are zero-length edges
+	seen := make(map[Point][]int)
+	for i, v := range vertices {
+		seen[v] = append(seen[v], i)
+	}
+
+	repeated := make(map[Point]bool)
+	for pt, idxs := range seen {
+		if len(idxs) < 2 {
+			continue
+		}
+
+		repeated[pt] = true
+		if len(idxs) == 2 && (idxs[1] == idxs[0]+1 || (idxs[0] == 0 && idxs[1] == n-1)) {
+			continue
+		}
+
+		issues = append(issues, Issue{RepeatedVertex, idxs, pt})
+	}
+
+	// Vertices in the middle of a straight run
+	for i, v := range vertices {
+		prev := vertices[(i+n-1)%n]
+		next := vertices[(i+1)%n]
+
+		if straight(prev, v, next) {
+			issues = append(issues, Issue{CollinearVertex, []int{i}, v})
+		}
+	}
+
+	issues = append(issues, edgeIntersections(edges, repeated)...)
+
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		return cmp.Compare(a.firstVertex(), b.firstVertex())
+	})
+
+	return issues
+}
+
+func (i Issue) firstVertex() int {
+	if len(i.Vertices) == 0 {
+		return -1
+	}
+
+	return slices.Min(i.Vertices)
+}
+
+type bounds struct {
+	idx  int
+	rect Rect
+}
+
+// Find edges that touch or cross each other
+// Edges are swept from left to right so only edges with overlapping x ranges are compared
+func edgeIntersections(edges []Edge, repeated map[Point]bool) []Issue {
+	n := len(edges)
+	issues := []Issue{}
+
+	sorted := []bounds{}
+	for i, e := range edges {
+		// Broken edges are reported on their own
+		if e.From == e.To || (e.From.X != e.To.X && e.From.Y != e.To.Y) {
+			continue
+		}
+
+		sorted = append(sorted, bounds{i, RectFromCorners(e.From, e.To)})
+	}
+
+	slices.SortFunc(sorted, func(a, b bounds) int {
+		return cmp.Compare(a.rect.Min.X, b.rect.Min.X)
+	})
+
+	for a, ea := range sorted {
+		for _, eb := range sorted[a+1:] {
+			if eb.rect.Min.X > ea.rect.Max.X {
+				break
+			}
+
+			overlap, ok := intersect(ea.rect, eb.rect)
+			if !ok {
+				continue
+			}
+
+			i, j := min(ea.idx, eb.idx), max(ea.idx, eb.idx)
+			adjacent := j == i+1 || (i == 0 && j == n-1)
+
+			if adjacent {
+				// Neighbouring edges share a vertex, anything more means the loop doubles back on itself
+				if overlap.Min != overlap.Max {
+					issues = append(issues, Issue{OverlappingEdges, []int{i, j}, overlap.Min})
+				}
+				continue
+			}
+
+			// Touching at a repeated vertex is already reported
+			if overlap.Min == overlap.Max && repeated[overlap.Min] {
+				continue
+			}
+
+			kind := CrossingEdges
+			if overlap.Min != overlap.Max {
+				kind = OverlappingEdges
+			}
+
+			issues = append(issues, Issue{kind, []int{i, j}, overlap.Min})
+		}
+	}
+
+	return issues
+}
+
+func intersect(r1, r2 Rect) (Rect, bool) {
+	r := Rect{
+		Point{max(r1.Min.X, r2.Min.X), max(r1.Min.Y, r2.Min.Y)},
+		Point{min(r1.Max.X, r2.Max.X), min(r1.Max.Y, r2.Max.Y)},
+	}
+
+	return r, r.Min.X <= r.Max.X && r.Min.Y <= r.Max.Y
+}
+
+// Check if `v` sits in the middle of a straight run from `prev` to `next`
+func straight(prev, v, next Point) bool {
+	if prev == v || v == next {
+		return false
+	}
+
+	return (prev.X == v.X && v.X == next.X && (prev.Y < v.Y) == (v.Y < next.Y)) ||
+		(prev.Y == v.Y && v.Y == next.Y && (prev.X < v.X) == (v.X < next.X))
+}
+
+// Simplify drops redundant vertices: back-to-back duplicates and vertices in the middle of a straight run
+// Returns the remaining vertices along with their indexes in the original list
+func Simplify(vertices []Point) ([]Point, []int) {
+	kept := []int{}
+
+	for i, v := range vertices {
+		if len(kept) > 0 && vertices[kept[len(kept)-1]] == v {
+			continue
+		}
+
+		// Dropping a vertex can make the one before it redundant too
+		for len(kept) >= 2 && straight(vertices[kept[len(kept)-2]], vertices[kept[len(kept)-1]], v) {
+			kept = kept[:len(kept)-1]
+		}
+
+		kept = append(kept, i)
+	}
+
+	// Tidy up where the end of the loop joins back to the start
+	for len(kept) >= 3 {
+		first := vertices[kept[0]]
+		second := vertices[kept[1]]
+		last := vertices[kept[len(kept)-1]]
+		beforeLast := vertices[kept[len(kept)-2]]
+
+		switch {
+		case last == first || straight(beforeLast, last, first):
+			kept = kept[:len(kept)-1]
+		case straight(last, first, second):
+			kept = kept[1:]
+		default:
+			goto done
+		}
+	}
+done:
+
+	result := make([]Point, len(kept))
+	for i, idx := range kept {
+		result[i] = vertices[idx]
+	}
+
+	return result, kept
+}
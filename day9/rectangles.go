@@ -0,0 +1,247 @@
+package day9
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+)
+
+// Red tile along with its position in the input, so tiles sharing a position stay distinct
+type indexedPoint struct {
+	idx int
+	pt  xy
+}
+
+// Find the largest rectangle using any two points as opposite corners without comparing every pair
+func largestRect(points []xy) (int, pair) {
+	area, i, j := bestPair(indexPoints(points, nil))
+	if area == 0 {
+		return 0, pair{}
+	}
+
+	return area, pair{points[i], points[j]}
+}
+
+func indexPoints(points []xy, excluded []int) []indexedPoint {
+	indexed := make([]indexedPoint, 0, len(points))
+	for i, pt := range points {
+		if !slices.Contains(excluded, i) {
+			indexed = append(indexed, indexedPoint{i, pt})
+		}
+	}
+
+	return indexed
+}
+
+// Find the indexes of the two points making the largest rectangle
+//
+// For corners running up and to the right, the bottom-left corner can always be swapped for a point on the
+// lower-left Pareto frontier (no other point is both left of and below it) without shrinking the rectangle.
+// The same goes for the top-right corner and the upper-right frontier. Along the two frontiers the best
+// partner for each point moves monotonically, so a divide and conquer search finds the best pair in
+// O(n log n). Flipping the y axis covers rectangles running down and to the right.
+func bestPair(points []indexedPoint) (int, int, int) {
+	if len(points) < 2 {
+		return 0, -1, -1
+	}
+
+	bestArea, best1, best2 := 0, -1, -1
+
+	for _, flip := range []bool{false, true} {
+		pts := flipY(points, flip)
+		lower := paretoLayer(pts, false)
+		upper := paretoLayer(pts, true)
+
+		if area, i, j := bestFrontierPair(lower, upper); area > bestArea {
+			bestArea, best1, best2 = area, i, j
+		}
+	}
+
+	// A point can sit on both frontiers and pair with itself, which only wins when every point is in
+	// the same place. Any two of them make the same single tile rectangle.
+	if best1 == best2 {
+		best1, best2 = points[0].idx, points[1].idx
+	}
+
+	return bestArea, best1, best2
+}
+
+func flipY(points []indexedPoint, flip bool) []indexedPoint {
+	if !flip {
+		return points
+	}
+
+	flipped := make([]indexedPoint, len(points))
+	for i, ip := range points {
+		flipped[i] = indexedPoint{ip.idx, xy{ip.pt.x, -ip.pt.y}}
+	}
+
+	return flipped
+}
+
+// Points not dominated by any other point, sorted by x ascending (and so y descending)
+// The lower layer holds points with nothing both left of and below them, the upper layer
+// points with nothing both right of and above them
+func paretoLayer(points []indexedPoint, upper bool) []indexedPoint {
+	sorted := slices.Clone(points)
+	slices.SortFunc(sorted, func(a, b indexedPoint) int {
+		return cmp.Or(cmp.Compare(a.pt.x, b.pt.x), cmp.Compare(a.pt.y, b.pt.y))
+	})
+
+	layer := []indexedPoint{}
+
+	if upper {
+		// Sweep right to left keeping points higher than everything to their right
+		for i := len(sorted) - 1; i >= 0; i-- {
+			ip := sorted[i]
+			if len(layer) == 0 || ip.pt.y > layer[len(layer)-1].pt.y {
+				layer = append(layer, ip)
+			}
+		}
+		slices.Reverse(layer)
+	} else {
+		// Sweep left to right keeping points lower than everything to their left
+		for _, ip := range sorted {
+			if len(layer) == 0 || ip.pt.y < layer[len(layer)-1].pt.y {
+				layer = append(layer, ip)
+			}
+		}
+	}
+
+	return layer
+}
+
+// Area of the rectangle with `lo` as the bottom-left corner and `hi` as the top-right
+// Pairs the wrong way round get a non-positive value so they never win, while keeping
+// the values monotone enough for the divide and conquer search
+func frontierArea(lo, hi xy) int {
+	w := hi.x - lo.x + 1
+	h := hi.y - lo.y + 1
+
+	if w <= 0 && h <= 0 {
+		return -w * h
+	}
+
+	return w * h
+}
+
+// Find the pair with the largest area between the lower and upper frontiers
+func bestFrontierPair(lower, upper []indexedPoint) (int, int, int) {
+	bestArea, best1, best2 := 0, -1, -1
+
+	var search func(lo, hi, optLo, optHi int)
+	search = func(lo, hi, optLo, optHi int) {
+		if lo > hi {
+			return
+		}
+
+		mid := (lo + hi) / 2
+		opt := optLo
+		midArea := frontierArea(lower[mid].pt, upper[optLo].pt)
+
+		for j := optLo + 1; j <= optHi; j++ {
+			if area := frontierArea(lower[mid].pt, upper[j].pt); area > midArea {
+				midArea = area
+				opt = j
+			}
+		}
+
+		if midArea > bestArea {
+			bestArea, best1, best2 = midArea, lower[mid].idx, upper[opt].idx
+		}
+
+		search(lo, mid-1, optLo, opt)
+		search(mid+1, hi, opt, optHi)
+	}
+
+	if len(lower) > 0 && len(upper) > 0 {
+		search(0, len(lower)-1, 0, len(upper)-1)
+	}
+
+	return bestArea, best1, best2
+}
+
+// Part of the space of pairs still to be searched for the k largest rectangles
+// Either every pair of points not yet excluded, or when `fixed` is set, pairs of that point
+// with a partner not yet excluded
+type rectSearch struct {
+	fixed    int
+	excluded []int
+
+	// Best pair in this part of the space
+	area   int
+	i1, i2 int
+}
+
+// Find the k largest rectangles using any two points as opposite corners
+//
+// Once the best pair (a, b) is found, the remaining pairs split into those without a, and those with a
+// but without b. Each part is searched for its own best pair and the best part is split again, so the
+// k largest take k rounds of the O(n log n) search instead of looking at every pair.
+func largestRects(points []xy, k int) []pair {
+	result := []pair{}
+	queue := &searchQueue{}
+
+	search := func(fixed int, excluded []int) {
+		s := rectSearch{fixed: fixed, excluded: excluded}
+		if fixed < 0 {
+			s.area, s.i1, s.i2 = bestPair(indexPoints(points, excluded))
+		} else {
+			s.area, s.i1, s.i2 = bestPartner(points, fixed, excluded)
+		}
+
+		if s.area > 0 {
+			heap.Push(queue, s)
+		}
+	}
+
+	search(-1, nil)
+
+	for len(result) < k && queue.Len() > 0 {
+		s := heap.Pop(queue).(rectSearch)
+		result = append(result, pair{points[s.i1], points[s.i2]})
+
+		if s.fixed < 0 {
+			// Pairs without the first point, and pairs with it but without the second
+			search(-1, append(slices.Clone(s.excluded), s.i1))
+			search(s.i1, append(slices.Clone(s.excluded), s.i1, s.i2))
+		} else {
+			// Pairs with the fixed point but without this partner
+			search(s.fixed, append(slices.Clone(s.excluded), s.i2))
+		}
+	}
+
+	return result
+}
+
+// Find the best partner for one point among those not excluded
+func bestPartner(points []xy, fixed int, excluded []int) (int, int, int) {
+	bestArea, best := 0, -1
+
+	for i, pt := range points {
+		if i == fixed || slices.Contains(excluded, i) {
+			continue
+		}
+
+		if area := area(pair{points[fixed], pt}); area > bestArea {
+			bestArea, best = area, i
+		}
+	}
+
+	return bestArea, fixed, best
+}
+
+// Max-heap of searches by the area of their best pair
+type searchQueue []rectSearch
+
+func (q searchQueue) Len() int           { return len(q) }
+func (q searchQueue) Less(i, j int) bool { return q[i].area > q[j].area }
+func (q searchQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *searchQueue) Push(x any)        { *q = append(*q, x.(rectSearch)) }
+
+func (q *searchQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,57 @@
+package day9
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Draw the theater floor as an SVG image using the original tile coordinates
+// Each tile is a 1x1 square centered on its coordinate. The loop is filled in green,
+// the part 1 rectangle is outlined in blue and the part 2 rectangle in orange.
+func renderSvg(out io.Writer, points []xy, part1Best, part2Best pair) {
+	minX, minY := points[0].x, points[0].y
+	maxX, maxY := minX, minY
+	for _, pt := range points {
+		minX, maxX = min(minX, pt.x), max(maxX, pt.x)
+		minY, maxY = min(minY, pt.y), max(maxY, pt.y)
+	}
+
+	// Scale strokes and markers to the size of the floor so they stay visible
+	size := max(maxX-minX, maxY-minY) + 1
+	stroke := max(float64(size)/400, 0.1)
+	pad := stroke * 10
+
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"%g %g %g %g\">\n",
+		float64(minX)-0.5-pad, float64(minY)-0.5-pad, float64(maxX-minX+1)+2*pad, float64(maxY-minY+1)+2*pad)
+
+	// Interior and the green tiles of the loop
+	coords := make([]string, len(points))
+	for i, pt := range points {
+		coords[i] = fmt.Sprintf("%d,%d", pt.x, pt.y)
+	}
+	fmt.Fprintf(out, "<polygon points=\"%s\" fill=\"#b8e6b8\" stroke=\"#2e8b57\" stroke-width=\"%g\" stroke-linejoin=\"round\"/>\n",
+		strings.Join(coords, " "), max(stroke, 1))
+
+	svgRect(out, part1Best, "#1e64c8", stroke)
+	svgRect(out, part2Best, "#ff8c00", stroke)
+
+	// Red tiles
+	for _, pt := range points {
+		fmt.Fprintf(out, "<circle cx=\"%d\" cy=\"%d\" r=\"%g\" fill=\"#d62828\"><title>%d,%d</title></circle>\n",
+			pt.x, pt.y, max(stroke*1.5, 0.5), pt.x, pt.y)
+	}
+
+	fmt.Fprintln(out, "</svg>")
+}
+
+// Outline the tiles covered by the rectangle between a pair of corners
+func svgRect(out io.Writer, p pair, color string, stroke float64) {
+	x := float64(min(p.pt1.x, p.pt2.x)) - 0.5
+	y := float64(min(p.pt1.y, p.pt2.y)) - 0.5
+	w := float64(max(p.pt1.x, p.pt2.x)-min(p.pt1.x, p.pt2.x)) + 1
+	h := float64(max(p.pt1.y, p.pt2.y)-min(p.pt1.y, p.pt2.y)) + 1
+
+	fmt.Fprintf(out, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"%s\" fill-opacity=\"0.25\" stroke=\"%s\" stroke-width=\"%g\"><title>%d,%d - %d,%d (%d)</title></rect>\n",
+		x, y, w, h, color, color, stroke, p.pt1.x, p.pt1.y, p.pt2.x, p.pt2.y, area(p))
+}
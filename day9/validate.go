@@ -0,0 +1,85 @@
+package day9
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cwmiller/advent-of-code-2025/day9/geometry"
+)
+
+// Validate the loop of red tiles, reporting problems against the input line numbers
+// Redundant vertices are only warnings, and are dropped if `repair` is set.
+// Any other problem is returned as an error since part 2 can't be solved with a broken loop.
+// With `verbose` set, the winding direction and enclosed area are reported as well.
+func checkLoop(out io.Writer, points []xy, lineNums []int, verbose bool, repair bool) ([]xy, []int, error) {
+	vertices := toVertices(points)
+
+	if repair {
+		simplified, kept := geometry.Simplify(vertices)
+		if dropped := len(vertices) - len(simplified); dropped > 0 || verbose {
+			fmt.Fprintf(out, "Repair: dropped %d redundant vertices\n", dropped)
+		}
+
+		keptLines := make([]int, len(kept))
+		keptPoints := make([]xy, len(kept))
+		for i, idx := range kept {
+			keptLines[i] = lineNums[idx]
+			keptPoints[i] = points[idx]
+		}
+
+		points, lineNums, vertices = keptPoints, keptLines, simplified
+	}
+
+	errs := []error{}
+	for _, issue := range geometry.Validate(vertices) {
+		lines := make([]string, len(issue.Vertices))
+		for i, idx := range issue.Vertices {
+			lines[i] = strconv.Itoa(lineNums[idx])
+		}
+
+		var msg string
+		switch issue.Kind {
+		case geometry.TooFewVertices:
+			msg = issue.Kind.String()
+		case geometry.CrossingEdges, geometry.OverlappingEdges:
+			msg = fmt.Sprintf("edges starting on lines %s: %s", strings.Join(lines, " and "), issue)
+		default:
+			msg = fmt.Sprintf("line %s: %s", strings.Join(lines, ", "), issue)
+		}
+
+		if issue.Kind.Fatal() {
+			errs = append(errs, errors.New(msg))
+		} else {
+			fmt.Fprintln(out, "Warning:", msg)
+		}
+	}
+
+	if verbose {
+		if len(errs) == 0 {
+			polygon, _ := geometry.NewPolygon(vertices)
+			fmt.Fprintln(out, "Winding:", polygon.Orientation())
+			fmt.Fprintln(out, "Area:", polygon.Area())
+		}
+
+		for _, err := range errs {
+			fmt.Fprintln(out, "Error:", err)
+		}
+
+		fmt.Fprintf(out, "%d vertices, %d errors\n", len(vertices), len(errs))
+		return points, lineNums, nil
+	}
+
+	return points, lineNums, errors.Join(errs...)
+}
+
+func toVertices(points []xy) []geometry.Point {
+	vertices := make([]geometry.Point, len(points))
+	for i, pt := range points {
+		vertices[i] = geometry.Point{X: pt.x, Y: pt.y}
+	}
+
+	return vertices
+}